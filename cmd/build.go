@@ -9,27 +9,30 @@ import (
 )
 
 // Build - собираем проект и запускаем сервер локально
-func Build(cnf string) {
+func Build(cnf string) *core.App {
 
 	start := time.Now()
 
 	var c = core.Process(cnf)
+	c.AddError(c.LoadTemplates())
 	c.ScanContent()
 	c.MakeIndexPage()
 	c.MakeTagIndexPage()
 	c.MakeDetailPages()
+	c.MakePages()
 	c.MakeTagPages()
 	c.MakeRSS()
 	c.MakeSiteMap()
 	c.MakeRobotsTxt()
 	c.MakeSearchJson()
 	c.MakePostCategories()
+	c.MakeCollectionPages()
 	c.CopyStaticFiles()
 
 	duration := time.Since(start)
 
 	rows := map[string]int{
-		"Страниц/постов": len(c.Posts),
+		"Страниц/постов": len(c.Posts) + len(c.Pages),
 		"Тэгов":          len(c.Tags),
 		"Категорий":      len(c.PostTypes),
 	}
@@ -44,5 +47,10 @@ func Build(cnf string) {
 	fmt.Fprintln(w, "Файл конфигурации - ", cnf)
 	fmt.Fprintln(w, "-------")
 	fmt.Fprintf(w, "%s\t%s\n", "Время сборки", duration)
+	if len(c.Errors) > 0 {
+		fmt.Fprintf(w, "%s\t%d\n", "Ошибок", len(c.Errors))
+	}
 	w.Flush()
+
+	return c
 }
@@ -18,7 +18,7 @@ func CreateNewPost(path string) {
 	path = "content/" + path
 	core.CreateDir(filepath.Dir(path))
 
-	markdownContent := fmt.Sprintf("---\ntitle: 111\ndate: %s\ndraft: false\ntags: [\"111\", \"222\"]\nimage: \"/cdn/123/111.png\"\ncover: \"/cdn/123/222.png\"\n---\n\n\n<!--more-->\n\n\n", time.Now().Format("2006-01-02T15:04:05Z"))
+	markdownContent := fmt.Sprintf("---\ntitle: 111\ndate: %s\ndraft: false\ntags: [\"111\", \"222\"]\nimage: \"/cdn/123/111.png\"\ncover: \"/cdn/123/222.png\"\n---\n\n\n<!--more-->\n\n\n", time.Now().Format(time.RFC3339))
 
 	f, err := os.Create(path)
 	if err != nil {
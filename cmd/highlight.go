@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/globalmac/boyar/core"
+	"log"
+	"os"
+)
+
+// MakeHighlightCSS - генерация CSS файла темы подсветки синтаксиса в static шаблона
+func MakeHighlightCSS(cfg string) {
+
+	config, err := core.LoadConfig(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dir := config.SourceDir + "/static/css"
+
+	err = core.CreateDir(dir)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	path := dir + "/highlight.css"
+
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer f.Close()
+
+	err = core.HighlightCSS(f, config.Markdown.Highlight)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	fmt.Printf("Стили подсветки синтаксиса (%s) сохранены: %s\n", config.Markdown.Highlight.Style, path)
+}
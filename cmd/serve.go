@@ -12,9 +12,68 @@ import (
 	"sync"
 )
 
+// buildErrors - ошибки последней сборки, которые показываются в браузере вместо страниц
+type buildErrors struct {
+	sync.RWMutex
+	errs []core.BuildError
+}
+
+func (b *buildErrors) set(c *core.App) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.errs = nil
+	if c != nil {
+		b.errs = c.Errors
+	}
+}
+
+// handler - файлы сборки; пока в последней сборке есть ошибки, на запросы страниц отдаётся страница с ошибками
+func (b *buildErrors) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.RLock()
+		errs := b.errs
+		b.RUnlock()
+
+		ext := filepath.Ext(r.URL.Path)
+		if len(errs) == 0 || (ext != "" && ext != ".html") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusInternalServerError)
+		err := core.ErrorOverlay(w, errs)
+		if err != nil {
+			log.Println(err)
+		}
+	})
+}
+
+// inDir - path совпадает с директорией dir или лежит внутри неё; пути сравниваются абсолютными,
+// чтобы themes не совпадало с themes-old, а пустая dir (например, themes_dir: "") - со всеми путями
+func inDir(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+
+	return absPath == absDir || strings.HasPrefix(absPath, strings.TrimSuffix(absDir, string(filepath.Separator))+string(filepath.Separator))
+}
+
 func Serve(cf string) {
 
-	Build(cf)
+	var lastBuild buildErrors
+	lastBuild.set(Build(cf))
 
 	config, err := core.LoadConfig(cf)
 	if err != nil {
@@ -23,7 +82,7 @@ func Serve(cf string) {
 
 	go func() {
 		mux := http.NewServeMux()
-		mux.Handle("/", http.FileServer(http.Dir(config.BuildDir)))
+		mux.Handle("/", lastBuild.handler(http.FileServer(http.Dir(config.BuildDir))))
 
 		server := http.Server{
 			Addr:    ":" + config.Port,
@@ -50,8 +109,8 @@ func Serve(cf string) {
 				if !ok {
 					return
 				}
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					Build(cf)
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					lastBuild.set(Build(cf))
 					fmt.Println("Изменен:", event.Name)
 				}
 			case err, ok := <-watcher.Errors:
@@ -76,7 +135,11 @@ func Serve(cf string) {
 			return nil
 		}
 		if info.Mode().IsDir() || info.IsDir() {
-			if strings.HasPrefix(path, "app/design") || strings.HasPrefix(path, "content") {
+			// Изменения в build_dir не отслеживаются: иначе каждая сборка запускала бы следующую
+			if inDir(path, config.BuildDir) {
+				return nil
+			}
+			if inDir(path, config.SourceDir) || inDir(path, config.ContentPath) || inDir(path, config.ThemesDir) {
 				return watcher.Add(path)
 			}
 		}
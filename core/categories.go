@@ -0,0 +1,118 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"path"
+	"sort"
+	"strings"
+)
+
+// inCategory - тип поста совпадает с категорией или вложен в неё по сегментам пути:
+// "posts/2024" входит в "posts", а "postscript" - нет
+func inCategory(postType, category string) bool {
+	return postType == category || strings.HasPrefix(postType, category+"/")
+}
+
+// buildCategories - дерево категорий из типов постов и разделов с _index.md; для вложенных
+// типов создаются и родительские категории, даже если в них нет своих постов
+func (core *App) buildCategories() {
+	core.categories = map[string]*types.Category{}
+	core.Categories = nil
+
+	var add func(postType string) *types.Category
+	add = func(postType string) *types.Category {
+		if c, ok := core.categories[postType]; ok {
+			return c
+		}
+
+		section := core.section(postType)
+		c := &types.Category{
+			Type:        postType,
+			Slug:        path.Base(postType),
+			Title:       section.Title,
+			Description: section.Description,
+		}
+		if c.Title == "" {
+			c.Title = c.Slug
+		}
+		core.categories[postType] = c
+
+		if parent := path.Dir(postType); parent != "." && parent != "/" {
+			c.Parent = add(parent)
+			c.Depth = c.Parent.Depth + 1
+			c.Parent.Children = append(c.Parent.Children, c)
+		} else {
+			core.Categories = append(core.Categories, c)
+		}
+
+		return c
+	}
+
+	for _, postType := range core.PostTypes {
+		add(postType)
+	}
+	for postType := range core.Sections {
+		if postType != "" {
+			add(postType)
+		}
+	}
+
+	for _, post := range listedPosts(core.Posts) {
+		c, ok := core.categories[post.Type]
+		if !ok {
+			continue
+		}
+		c.Count++
+		for ; c != nil; c = c.Parent {
+			c.Total++
+		}
+	}
+
+	var sortTree func(categories []*types.Category)
+	sortTree = func(categories []*types.Category) {
+		sort.Slice(categories, func(i, j int) bool {
+			return categories[i].Slug < categories[j].Slug
+		})
+		for _, c := range categories {
+			sortTree(c.Children)
+		}
+	}
+	sortTree(core.Categories)
+}
+
+// categoryList - все категории дерева в порядке обхода: родитель, затем его подкатегории
+func (core *App) categoryList() []*types.Category {
+	var list []*types.Category
+
+	var walk func(categories []*types.Category)
+	walk = func(categories []*types.Category) {
+		for _, c := range categories {
+			list = append(list, c)
+			walk(c.Children)
+		}
+	}
+	walk(core.Categories)
+
+	return list
+}
+
+// category - категория по типу постов
+func (core *App) category(postType string) *types.Category {
+	return core.categories[postType]
+}
+
+// breadcrumbs - хлебные крошки: главная, родительские категории и сама категория postType
+func (core *App) breadcrumbs(postType string) []types.Breadcrumb {
+	crumbs := []types.Breadcrumb{{Title: "Главная", URL: core.SiteConfig.BaseURL + "/"}}
+
+	c := core.category(postType)
+	if c == nil {
+		return crumbs
+	}
+
+	for _, a := range append(c.Ancestors(), c) {
+		crumbs = append(crumbs, types.Breadcrumb{Title: a.Title, URL: core.SiteConfig.BaseURL + a.Permarlink()})
+	}
+
+	return crumbs
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"fmt"
+	"github.com/globalmac/boyar/types"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CollectionConfig - именованная выборка постов (секция collections: в конфиге), доступна в шаблонах
+// как .Site.Collections.<имя>. Фильтры: type (с вложенными типами), tags (все из списка, по слагам),
+// author, from и to (даты включительно), params (значения из params: во front matter). С url или
+// per_page выборка собирается в свои страницы списка (по умолчанию /<имя>/)
+type CollectionConfig struct {
+	Type    string                 `yaml:"type"`
+	Tags    []string               `yaml:"tags"`
+	Author  string                 `yaml:"author"`
+	From    string                 `yaml:"from"`
+	To      string                 `yaml:"to"`
+	Params  map[string]interface{} `yaml:"params"`
+	Sort    string                 `yaml:"sort"`
+	Order   string                 `yaml:"order"`
+	Limit   int                    `yaml:"limit"`
+	Offset  int                    `yaml:"offset"`
+	Title   string                 `yaml:"title"`
+	URL     string                 `yaml:"url"`
+	PerPage int                    `yaml:"per_page"`
+	Layout  string                 `yaml:"layout"`
+}
+
+// HasPages - для выборки собираются свои страницы списка
+func (c CollectionConfig) HasPages() bool {
+	return c.URL != "" || c.PerPage > 0
+}
+
+// collectionDate - граница диапазона дат; дата без времени в to включает весь день
+func (core *App) collectionDate(s string, end bool) (time.Time, error) {
+	t, err := parseDate(s, core.location())
+	if err != nil || !end {
+		return t, err
+	}
+
+	if len(strings.TrimSpace(s)) <= len("2006-01-02") {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	return t, nil
+}
+
+// hasTag - тег в списке тегов поста; сравниваются слаги, как на страницах тегов: Go и go - один тег
+func hasTag(tags []string, tag string) bool {
+	slug := slugify(tag)
+	for _, t := range tags {
+		if slugify(t) == slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasParam - значение params: поста равно value или (для списка) содержит его
+func hasParam(params map[string]interface{}, key string, value interface{}) bool {
+	v, ok := params[key]
+	if !ok {
+		return false
+	}
+
+	pv, want := reflect.ValueOf(v), reflect.ValueOf(value)
+	if equalValues(pv, want) {
+		return true
+	}
+
+	return indirect(pv).Kind() == reflect.Slice && containsValue(pv, want)
+}
+
+// collection - посты выборки: фильтры, сортировка (по умолчанию по дате, сначала новые), offset и limit.
+// Скрытые посты (unlisted: true) в выборки не попадают
+func (core *App) collection(cfg CollectionConfig) (types.Posts, error) {
+	var from, to time.Time
+	var err error
+
+	if cfg.From != "" {
+		if from, err = core.collectionDate(cfg.From, false); err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+	}
+	if cfg.To != "" {
+		if to, err = core.collectionDate(cfg.To, true); err != nil {
+			return nil, fmt.Errorf("to: %w", err)
+		}
+	}
+
+	var posts types.Posts
+
+	for _, post := range listedPosts(core.Posts) {
+		if cfg.Type != "" && !inCategory(post.Type, strings.Trim(cfg.Type, "/")) {
+			continue
+		}
+		if cfg.Author != "" && post.Author != cfg.Author {
+			continue
+		}
+		if !from.IsZero() && post.Date.Before(from) {
+			continue
+		}
+		if !to.IsZero() && post.Date.After(to) {
+			continue
+		}
+
+		matched := true
+		for _, tag := range cfg.Tags {
+			if !hasTag(post.Tags, tag) {
+				matched = false
+				break
+			}
+		}
+		for key, value := range cfg.Params {
+			if !matched {
+				break
+			}
+			matched = hasParam(post.Params, key, value)
+		}
+
+		if matched {
+			posts = append(posts, post)
+		}
+	}
+
+	posts = sortPosts(posts, cfg.Sort, cfg.Order)
+
+	if cfg.Offset > 0 {
+		if cfg.Offset >= len(posts) {
+			return types.Posts{}, nil
+		}
+		posts = posts[cfg.Offset:]
+	}
+	if cfg.Limit > 0 && cfg.Limit < len(posts) {
+		posts = posts[:cfg.Limit]
+	}
+
+	return posts, nil
+}
+
+// makeCollections - выборки из конфига; считаются один раз за сборку после сканирования контента
+func (core *App) makeCollections() {
+	core.Collections = map[string]types.Posts{}
+
+	for name, cfg := range core.SiteConfig.Collections {
+		posts, err := core.collection(cfg)
+		if err != nil {
+			core.AddError(fmt.Errorf("коллекция %s: %w", name, err))
+			continue
+		}
+
+		core.Collections[name] = posts
+	}
+}
+
+// MakeCollectionPages - страницы списков выборок с url или per_page: шаблон layout,
+// затем collection.html → posts.html
+func (core *App) MakeCollectionPages() {
+	names := make([]string, 0, len(core.SiteConfig.Collections))
+	for name := range core.SiteConfig.Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cfg := core.SiteConfig.Collections[name]
+		posts, ok := core.Collections[name]
+		if !ok || !cfg.HasPages() {
+			continue
+		}
+
+		url := cfg.URL
+		if url == "" {
+			url = "/" + name + "/"
+		}
+		url, _ = pageURL(url, name)
+		base := strings.TrimSuffix(url, path.Ext(url))
+
+		tplName, found := core.lookupTemplate("collection.html", "posts.html")
+		if cfg.Layout != "" {
+			tplName, found = core.lookupTemplate(cfg.Layout+".html", cfg.Layout)
+		}
+		if !found {
+			core.AddError(fmt.Errorf("коллекция %s: шаблон не найден", name))
+			continue
+		}
+
+		section := types.Section{Type: name, Title: cfg.Title}
+		if section.Title == "" {
+			section.Title = name
+		}
+
+		for _, pager := range core.paginate(posts, cfg.PerPage, base, url) {
+			data := map[string]interface{}{
+				"Posts":       pager.Posts,
+				"Collection":  name,
+				"Section":     section,
+				"Paginator":   pager,
+				"CurrentPage": pager.PageNumber,
+				"TotalPages":  pager.TotalPages,
+			}
+
+			_, fileName := pageURL(pager.URL, "")
+			err := core.SaveAsHTML(fileName, tplName, data)
+			if err != nil {
+				core.AddError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCollection(t *testing.T) {
+	day := func(m, d int) time.Time { return time.Date(2024, time.Month(m), d, 12, 0, 0, 0, time.UTC) }
+
+	core := &App{Posts: types.Posts{
+		{Slug: "a", Type: "news", Date: day(1, 10), Tags: []string{"Go", "Web"}, Author: "Анна"},
+		{Slug: "b", Type: "news/2024", Date: day(2, 5), Tags: []string{"go"}, Params: map[string]interface{}{"level": "easy"}},
+		{Slug: "c", Type: "posts", Date: day(2, 6), Tags: []string{"GO"}, Params: map[string]interface{}{"level": []interface{}{"easy", "hard"}}},
+		{Slug: "d", Type: "news", Date: day(3, 1), Tags: []string{"go"}, Unlisted: true},
+		{Slug: "e", Type: "newsletter", Date: day(3, 2), Tags: []string{"rust"}},
+	}}
+
+	tests := []struct {
+		name string
+		cfg  CollectionConfig
+		want []string
+	}{
+		{name: "тип с вложенными", cfg: CollectionConfig{Type: "news"}, want: []string{"b", "a"}},
+		{name: "теги по слагам", cfg: CollectionConfig{Tags: []string{"go"}}, want: []string{"c", "b", "a"}},
+		{name: "все теги из списка", cfg: CollectionConfig{Tags: []string{"go", "web"}}, want: []string{"a"}},
+		{name: "автор", cfg: CollectionConfig{Author: "Анна"}, want: []string{"a"}},
+		{name: "to включает весь день", cfg: CollectionConfig{From: "2024-02-01", To: "2024-02-05"}, want: []string{"b"}},
+		{name: "params", cfg: CollectionConfig{Params: map[string]interface{}{"level": "easy"}}, want: []string{"c", "b"}},
+		{name: "сортировка, offset, limit", cfg: CollectionConfig{Sort: "date", Order: "asc", Offset: 1, Limit: 2}, want: []string{"b", "c"}},
+		{name: "offset больше списка", cfg: CollectionConfig{Offset: 10}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, err := core.collection(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, post := range posts {
+				got = append(got, post.Slug)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := core.collection(CollectionConfig{From: "вчера"}); err == nil {
+		t.Error("ожидалась ошибка для неизвестного формата даты")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/globalmac/boyar/types"
+	"github.com/yuin/goldmark"
 	"html/template"
 	"io"
 	"io/fs"
@@ -16,35 +17,63 @@ import (
 )
 
 type App struct {
-	SiteConfig  SiteConfig
-	ContentDir  string
-	OutputDir   string
-	TemplateDir string
-	Posts       types.Posts
-	Tags        types.Tags
-	PostTypes   []string
+	SiteConfig   SiteConfig
+	ContentDir   string
+	OutputDir    string
+	TemplateDir  string
+	Posts        types.Posts
+	Pages        types.Posts
+	Sections     map[string]types.Section
+	Categories   []*types.Category
+	Collections  map[string]types.Posts
+	Menus        map[string]types.Menu
+	Tags         types.Tags
+	PostTypes    []string
+	Shortcodes   *template.Template
+	markdown     map[string]goldmark.Markdown
+	images       *imageProcessor
+	gitInfo      map[string]types.GitInfo
+	templates    map[string]*template.Template
+	templateErrs map[string]error
+	Errors       []BuildError
+	loc          *time.Location
+	categories   map[string]*types.Category
+	written      map[string]bool
 }
 
 type SiteConfig struct {
-	BaseURL         string            `yaml:"baseURL"`
-	Name            string            `yaml:"site_name"`
-	Title           string            `yaml:"title"`
-	Author          string            `yaml:"author"`
-	SftpPort        string            `yaml:"sftp_port"`
-	SftpServer      string            `yaml:"sftp_server"`
-	SftpLogin       string            `yaml:"sftp_login"`
-	SftpPassword    string            `yaml:"sftp_pass"`
-	Keywords        string            `yaml:"keywords"`
-	Description     string            `yaml:"description"`
-	Port            string            `yaml:"port"`
-	ContentPath     string            `yaml:"content_dir"`
-	BuildDir        string            `yaml:"build_dir"`
-	SourceDir       string            `yaml:"source_dir"`
-	Pages           []string          `yaml:"pages"`
-	PostTypesValues map[string]string `yaml:"post_types"`
-	PerPageIndex    int               `yaml:"per_page_index"`
-	PerPageCategory int               `yaml:"per_page_category"`
-	PerPageTag      int               `yaml:"per_page_tag"`
+	BaseURL         string                       `yaml:"baseURL"`
+	Name            string                       `yaml:"site_name"`
+	Title           string                       `yaml:"title"`
+	Author          string                       `yaml:"author"`
+	SftpPort        string                       `yaml:"sftp_port"`
+	SftpServer      string                       `yaml:"sftp_server"`
+	SftpLogin       string                       `yaml:"sftp_login"`
+	SftpPassword    string                       `yaml:"sftp_pass"`
+	Keywords        string                       `yaml:"keywords"`
+	Description     string                       `yaml:"description"`
+	Port            string                       `yaml:"port"`
+	ContentPath     string                       `yaml:"content_dir"`
+	BuildDir        string                       `yaml:"build_dir"`
+	SourceDir       string                       `yaml:"source_dir"`
+	Pages           []string                     `yaml:"pages"`
+	PostTypesValues map[string]PostTypeConfig    `yaml:"post_types"`
+	PerPageIndex    int                          `yaml:"per_page_index"`
+	PerPageCategory int                          `yaml:"per_page_category"`
+	PerPageTag      int                          `yaml:"per_page_tag"`
+	Markdown        MarkdownConfig               `yaml:"markdown"`
+	Links           LinkPolicy                   `yaml:"links"`
+	ImageProcessing ImageProcessingConfig        `yaml:"image_processing"`
+	Embeds          map[string]EmbedConfig       `yaml:"embeds"`
+	Summary         SummaryConfig                `yaml:"summary"`
+	GitInfo         bool                         `yaml:"git_info"`
+	Timezone        string                       `yaml:"timezone"`
+	Theme           Themes                       `yaml:"theme"`
+	ThemesDir       string                       `yaml:"themes_dir"`
+	Lists           map[string]ListSort          `yaml:"lists"`
+	Pagination      PaginationConfig             `yaml:"pagination"`
+	Collections     map[string]CollectionConfig  `yaml:"collections"`
+	Menus           map[string][]types.MenuEntry `yaml:"menus"`
 }
 
 func Process(cf string) *App {
@@ -70,6 +99,18 @@ func Process(cf string) *App {
 func (core *App) ScanContent() {
 	var paths []string
 
+	err := core.loadShortcodes()
+	if err != nil {
+		core.AddError(err)
+	}
+
+	if core.SiteConfig.GitInfo {
+		core.gitInfo, err = loadGitInfo(core.ContentDir)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
 	filepath.Walk(core.ContentDir, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -92,20 +133,75 @@ func (core *App) ScanContent() {
 
 		data, err := os.ReadFile(path)
 		if err != nil {
-			log.Println(err)
+			core.contentError(path, "", err)
+			continue
 		}
 
-		fmd, body := markdownParser(string(data))
+		fmd, body, err := markdownParser(string(data))
+		if err != nil {
+			core.contentError(path, "", err)
+		}
+
+		if filepath.Base(path) == sectionFile {
+			postType := strings.Replace(folder, "/", "", 1)
+			if core.Sections == nil {
+				core.Sections = map[string]types.Section{}
+			}
+			core.Sections[postType] = core.makeSection(postType, path, fmd, body)
+			continue
+		}
 
 		post.Type = strings.Replace(folder, "/", "", 1)
+		post.IsPage = post.Type == ""
 		post.Slug = filename
 		post.Slug = slugify(filename)
 		post.Title = fmd.Title
 		post.Cover = fmd.Cover
 		post.Image = fmd.Image
-		post.Date = fmd.Date
+		post.CoverSet, err = core.imageProcessing().Process(post.Cover, path)
+		if err != nil {
+			core.contentError(path, "cover", err)
+		}
+		post.ImageSet, err = core.imageProcessing().Process(post.Image, path)
+		if err != nil {
+			core.contentError(path, "image", err)
+		}
+		post.Date, err = parseDate(fmd.Date, core.location())
+		if err != nil {
+			core.contentError(path, "date", err)
+		}
+		post.Lastmod = post.Date
+		if gi, ok := core.postGitInfo(path); ok {
+			post.GitInfo = gi
+			post.Lastmod = gi.CommitDate.In(core.location())
+		}
+		if fmd.Lastmod != "" {
+			lastmod, err := parseDate(fmd.Lastmod, core.location())
+			if err != nil {
+				core.contentError(path, "lastmod", err)
+			} else {
+				post.Lastmod = lastmod
+			}
+		}
+		typeConfig := core.postTypeConfig(post.Type)
+		post.Layout = fmd.Layout
+		if post.Layout == "" && !post.IsPage {
+			post.Layout = typeConfig.Layout
+		}
+		post.Menus = fmd.Menu
+		if post.IsPage {
+			post.URL, _ = pageURL(fmd.URL, post.Slug)
+		} else if typeConfig.Permalink != "" {
+			post.URL, _ = pageURL(expandPermalink(typeConfig.Permalink, post), post.Slug)
+		}
 		post.Tags = fmd.Tags
 		post.Description = fmd.Description
+		post.Keywords = fmd.Keywords
+		post.Weight = fmd.Weight
+		post.Pinned = fmd.Pinned
+		post.Unlisted = fmd.Unlisted
+		post.NoIndex = fmd.NoIndex
+		post.Params = fmd.Params
 		post.Author = fmd.Author
 		post.SourceUrl = fmd.SourceUrl
 
@@ -116,25 +212,50 @@ func (core *App) ScanContent() {
 		}
 
 		// Collect all post types
-		if !sliceContains(core.PostTypes, post.Type) {
+		if !post.IsPage && !sliceContains(core.PostTypes, post.Type) {
 			core.PostTypes = append(core.PostTypes, post.Type)
 		}
 
-		post.Content, err = markdownRender(body)
+		restore := func(s string) string { return s }
+		if hasShortcodes(body) {
+			expanded, restoreShortcodes, err := core.renderShortcodes(body, post)
+			if err != nil {
+				core.contentError(path, "", err)
+			} else {
+				body, restore = expanded, restoreShortcodes
+			}
+		}
+
+		links, err := core.SiteConfig.Links.ForPost(core.SiteConfig.BaseURL, fmd.Links)
 		if err != nil {
-			log.Println(err)
+			core.contentError(path, "links", err)
+		}
+
+		post.Content, err = core.markdownRender(body, post.Type, markdownContext(links, path))
+		if err != nil {
+			core.contentError(path, "", err)
 		}
+		post.Content = restore(post.Content)
 
-		post.Summary, post.Reminder = splitContent(post.Content)
-		post.SummaryClean = removeHTMLTags(post.Summary)
+		// Анонс рендерится в своём контексте: id заголовков и ссылки-сноски тела поста на него не влияют
+		err = core.makeSummary(&post, fmd, func(summary string) (string, error) {
+			return core.markdownRender(summary, post.Type, markdownContext(links, path))
+		})
+		if err != nil {
+			core.contentError(path, "summary", err)
+		}
 
 		if post.Status == "published" {
-			core.Posts = append(core.Posts, post)
+			if post.IsPage {
+				core.Pages = append(core.Pages, post)
+			} else {
+				core.Posts = append(core.Posts, post)
+			}
 		}
 	}
 
 	// Retrieve tags
-	for _, post := range core.Posts {
+	for _, post := range core.filterPosts(core.Posts, PostTypeConfig.HasTags) {
 		for _, t := range post.Tags {
 			tag := core.Tags.Find(t)
 
@@ -146,73 +267,39 @@ func (core *App) ScanContent() {
 			}
 		}
 	}
+
+	core.buildCategories()
+	core.makeCollections()
+	core.buildMenus()
 }
 
-func splitContent(content string) (summary, remainder string) {
+func splitContent(content string) (summary, remainder string, found bool) {
 	parts := strings.SplitN(content, "<!--more-->", 2)
 	if len(parts) == 2 {
-		return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
 	}
-	return content, ""
+	return content, "", false
 }
 
 func (core *App) MakeIndexPage() {
-	sortedPosts := core.Posts
-	sort.Sort(types.PostsByDate(sortedPosts))
-
-	if len(core.SiteConfig.Pages) > 0 {
-		for _, page := range core.SiteConfig.Pages {
-			if _, err := os.Stat(page); os.IsNotExist(err) {
-				err := core.SaveAsHTML(page, page, map[string]interface{}{})
-				if err != nil {
-					log.Println(err)
-				}
-			}
-		}
-	}
-
-	perPage := core.SiteConfig.PerPageIndex
-	dividedPosts := DividePosts(sortedPosts, perPage, "all")
-
-	if len(dividedPosts) > 0 {
-		for pageNum, pagePosts := range dividedPosts {
-
-			pp := pageNum + 1
-
-			data := map[string]interface{}{
-				"Posts":       pagePosts,
-				"IsHome":      true,
-				"CurrentPage": pp,
-				"TotalPages":  len(dividedPosts),
-			}
-
-			fileName := fmt.Sprintf("/%d.html", pageNum+1)
-			if pp == 1 {
-				fileName = fmt.Sprintf("index.html")
-			}
-			err := core.SaveAsHTML(fileName, "index.html", data)
-			if err != nil {
-				log.Println(err)
-			}
-		}
-
-	} else {
+	home := core.SiteConfig.Lists["home"]
+	sortedPosts := pinFirst(sortPosts(core.filterPosts(core.Posts, PostTypeConfig.OnHome), home.Sort, home.Order))
 
+	for _, pager := range core.paginate(sortedPosts, core.SiteConfig.PerPageIndex, "", "/") {
 		data := map[string]interface{}{
-			"Posts":       sortedPosts,
+			"Posts":       pager.Posts,
 			"IsHome":      true,
-			"CurrentPage": 0,
-			"TotalPages":  len(dividedPosts),
+			"Paginator":   pager,
+			"CurrentPage": pager.PageNumber,
+			"TotalPages":  pager.TotalPages,
 		}
 
-		fileName := fmt.Sprintf("index.html")
+		_, fileName := pageURL(pager.URL, "")
 		err := core.SaveAsHTML(fileName, "index.html", data)
 		if err != nil {
-			log.Println(err)
+			core.AddError(err)
 		}
-
 	}
-
 }
 
 func (core *App) MakeDetailPages() {
@@ -220,18 +307,21 @@ func (core *App) MakeDetailPages() {
 	sortedTags := core.Tags
 	sort.Sort(types.TagsByName(sortedTags))
 
-	if len(sortedTags) > 0 {
-		for _, post := range core.Posts {
-			fileName := fmt.Sprintf("%s/%s.html", post.Type, post.Slug)
-			data := map[string]interface{}{
-				"Post":       post,
-				"IsSingular": true,
-				"Tags":       sortedTags,
-			}
-			err := core.SaveAsHTML(fileName, "detail.html", data)
-			if err != nil {
-				log.Println(err)
-			}
+	for _, post := range core.Posts {
+		fileName := fmt.Sprintf("%s/%s.html", post.Type, post.Slug)
+		if post.URL != "" {
+			_, fileName = pageURL(post.URL, post.Slug)
+		}
+		data := map[string]interface{}{
+			"Post":        post,
+			"IsSingular":  true,
+			"Tags":        sortedTags,
+			"Category":    core.category(post.Type),
+			"Breadcrumbs": core.breadcrumbs(post.Type),
+		}
+		err := core.SaveAsHTML(fileName, core.postTemplate(post), data)
+		if err != nil {
+			core.AddError(err)
 		}
 	}
 
@@ -246,7 +336,7 @@ func (core *App) MakeTagIndexPage() {
 			"Tags": sortedTags,
 		})
 		if err != nil {
-			log.Println(err)
+			core.AddError(err)
 		}
 	}
 }
@@ -256,35 +346,33 @@ func (core *App) MakeTagPages() {
 	sortedTags := core.Tags
 	sort.Sort(types.TagsByName(sortedTags))
 
+	taggedPosts := core.filterPosts(core.Posts, PostTypeConfig.HasTags)
+	tagsSort := core.SiteConfig.Lists["tags"]
+
 	if len(sortedTags) > 0 {
 		for i, tag := range sortedTags {
-			sortedTags[i].CountPosts = len(core.Posts.FindByTag(tag.Name))
+			sortedTags[i].CountPosts = len(taggedPosts.FindByTag(tag.Name))
 		}
 		for _, tag := range core.Tags {
-			perPage := core.SiteConfig.PerPageTag
-			tag.Posts = core.Posts.FindByTag(tag.Name)
-			dividedPosts := DividePosts(tag.Posts, perPage, "all")
-			for pageNum, tagPosts := range dividedPosts {
-
-				pp := pageNum + 1
+			tag.Posts = sortPosts(taggedPosts.FindByTag(tag.Name), tagsSort.Sort, tagsSort.Order)
+			pagers := core.paginate(tag.Posts, core.SiteConfig.PerPageTag, "tags/"+tag.Slug, "/tags/"+tag.Slug+".html")
 
+			for _, pager := range pagers {
 				data := map[string]interface{}{
-					"TagPosts":    tagPosts,
+					"TagPosts":    pager.Posts,
 					"IsArchive":   true,
-					"CurrentPage": pp,
+					"Paginator":   pager,
+					"CurrentPage": pager.PageNumber,
 					"Tag":         tag,
 					"PostTypes":   core.PostTypes,
 					"Tags":        sortedTags,
-					"TotalPages":  len(dividedPosts),
+					"TotalPages":  pager.TotalPages,
 				}
 
-				fileName := fmt.Sprintf("tags/%d/%s.html", pageNum+1, tag.Slug)
-				if pp == 1 {
-					fileName = fmt.Sprintf("tags/%s.html", tag.Slug)
-				}
+				_, fileName := pageURL(pager.URL, "")
 				err := core.SaveAsHTML(fileName, "tag.html", data)
 				if err != nil {
-					log.Println(err)
+					core.AddError(err)
 				}
 			}
 
@@ -294,14 +382,22 @@ func (core *App) MakeTagPages() {
 }
 
 func (core *App) MakeRSS() {
-	sortedPosts := core.Posts
+	sortedPosts := core.filterPosts(core.Posts, PostTypeConfig.InRSS)
 	sort.Sort(types.PostsByDate(sortedPosts))
 
 	if len(sortedPosts) > 0 {
 
+		var lastBuild time.Time
+		for _, post := range sortedPosts {
+			if post.Lastmod.After(lastBuild) {
+				lastBuild = post.Lastmod
+			}
+		}
+
 		data := map[string]interface{}{
-			"Posts": sortedPosts,
-			"Site":  core.SiteConfig,
+			"Posts":     sortedPosts,
+			"Site":      core.SiteConfig,
+			"LastBuild": lastBuild,
 		}
 
 		rssTemplate := `
@@ -311,12 +407,13 @@ func (core *App) MakeRSS() {
 		<title>{{ .Site.Title }}</title>
 		<link>{{ $baseURL }}</link>
 		<description>{{ .Site.Description }}</description>
+		<lastBuildDate>{{ .LastBuild.Format "Mon, 02 Jan 2006 15:04:05 -0700" }}</lastBuildDate>
 		{{ range .Posts }}
 		<item>
 			<title>{{ .Title }}</title>
 			<link>{{ $baseURL }}{{ .Permarlink }}</link>
 			<description>{{ .SummaryClean }}</description>
-			<pubDate>{{ .Date.Format "2006-01-02T15:04:05" }}</pubDate>
+			<pubDate>{{ .Date.Format "Mon, 02 Jan 2006 15:04:05 -0700" }}</pubDate>
 		</item>
 		{{ end }}
 	</channel>
@@ -340,20 +437,21 @@ func (core *App) MakeRSS() {
 
 func (core *App) MakeSearchJson() {
 
-	sortedPosts := core.Posts
+	sortedPosts := core.filterPosts(core.Posts, PostTypeConfig.InSearch)
 	sort.Sort(types.PostsByDate(sortedPosts))
 
 	if len(sortedPosts) > 0 {
 
 		type SearchBlock struct {
-			Url   string `json:"k"`
-			Title string `json:"v"`
+			Url         string `json:"k"`
+			Title       string `json:"v"`
+			Description string `json:"d"`
 		}
 		var data []SearchBlock
 		if len(sortedPosts) > 0 {
 			for _, post := range sortedPosts {
 				data = append(data, SearchBlock{
-					post.Title, core.SiteConfig.BaseURL + post.Permarlink(),
+					post.Title, core.SiteConfig.BaseURL + post.Permarlink(), post.Description,
 				})
 			}
 			if len(data) > 0 {
@@ -370,54 +468,67 @@ func (core *App) MakeSearchJson() {
 
 func (core *App) MakePostCategories() {
 
-	if len(core.PostTypes) > 0 {
+	if len(core.Categories) > 0 {
 
-		for _, postType := range core.PostTypes {
+		for _, category := range core.categoryList() {
+
+			postType := category.Type
 
 			var posts types.Posts
 
-			for _, post := range core.Posts {
-				if post.Type == postType {
-					posts = append(posts, post)
-				} else if strings.HasPrefix(post.Type, postType) {
+			for _, post := range listedPosts(core.Posts) {
+				if inCategory(post.Type, postType) {
 					posts = append(posts, post)
 				}
 			}
 
+			// Сортировка и размер страницы: _index.md → post_types → общие настройки
+			section := core.section(postType)
+			typeConfig := core.postTypeConfig(postType)
+			sortBy, order := section.Sort, section.Order
+			if sortBy == "" {
+				sortBy, order = typeConfig.Sort, typeConfig.Order
+			}
+			posts = pinFirst(sortPosts(posts, sortBy, order))
+
 			perPage := core.SiteConfig.PerPageCategory
-			dividedPosts := DividePosts(posts, perPage, postType)
+			if section.PerPage > 0 {
+				perPage = section.PerPage
+			} else if typeConfig.PerPage > 0 {
+				perPage = typeConfig.PerPage
+			}
+			pagers := core.paginate(posts, perPage, postType, category.Permarlink())
 
 			sortedTags := core.Tags
 			sort.Sort(types.TagsByName(sortedTags))
 
 			if len(sortedTags) > 0 {
+				taggedPosts := core.filterPosts(core.Posts, PostTypeConfig.HasTags)
 				for i, tag := range sortedTags {
-					sortedTags[i].CountPosts = len(core.Posts.FindByTag(tag.Name))
+					sortedTags[i].CountPosts = len(taggedPosts.FindByTag(tag.Name))
 				}
 			}
 
-			for pageNum, pagePosts := range dividedPosts {
-
-				pp := pageNum + 1
+			for _, pager := range pagers {
 
 				data := map[string]interface{}{
-					"Posts":       pagePosts,
+					"Posts":       pager.Posts,
 					"PostType":    postType,
+					"Section":     section,
+					"Category":    category,
+					"Breadcrumbs": core.breadcrumbs(postType),
 					"PostTypes":   core.PostTypes,
 					"Tags":        sortedTags,
 					"PerPage":     perPage,
-					"CurrentPage": pp,
-					"TotalPages":  len(dividedPosts),
+					"Paginator":   pager,
+					"CurrentPage": pager.PageNumber,
+					"TotalPages":  pager.TotalPages,
 				}
 
-				fileName := fmt.Sprintf("%s/page/%d.html", postType, pageNum+1)
-				if pp == 1 {
-					fileName = fmt.Sprintf("%s/index.html", postType)
-				}
-
-				err := core.SaveAsHTML(fileName, "posts.html", data)
+				_, fileName := pageURL(pager.URL, "")
+				err := core.SaveAsHTML(fileName, core.listTemplate(postType), data)
 				if err != nil {
-					log.Println(err)
+					core.AddError(err)
 				}
 
 			}
@@ -429,9 +540,12 @@ func (core *App) MakePostCategories() {
 }
 
 func (core *App) MakeSiteMap() {
-	sortedPosts := core.Posts
+	sortedPosts := indexedPosts(core.filterPosts(core.Posts, PostTypeConfig.InSitemap))
 	sort.Sort(types.PostsByDate(sortedPosts))
 
+	// Отдельные страницы в sitemap идут вместе с постами
+	sortedPosts = append(indexedPosts(core.Pages), sortedPosts...)
+
 	if len(sortedPosts) > 0 {
 
 		data := map[string]interface{}{
@@ -446,7 +560,7 @@ func (core *App) MakeSiteMap() {
 		{{ range .Posts }}
 		<url>
 			<loc>{{ $baseURL }}{{ .Permarlink }}</loc>
-			<lastmod>{{ .Date.Format "2006-01-02T15:04:05" }}</lastmod>
+			<lastmod>{{ .Lastmod.Format "2006-01-02T15:04:05Z07:00" }}</lastmod>
 		</url>
 		{{ end }}
 	</channel>
@@ -494,11 +608,20 @@ Sitemap: {{ .Url }}/sitemap.xml`)
 }
 
 func (core *App) SaveAsHTML(fileName, templateName string, data map[string]interface{}) error {
-	tpl := compileTemplate(templateName, core)
+	// Страницы с одним адресом перезаписали бы друг друга (url: "/" у страницы и главная):
+	// файл собирается один раз, повторная запись - ошибка сборки
+	if core.written[fileName] {
+		return fmt.Errorf("%s: адрес уже занят другой страницей сборки (%s), файл не перезаписан", fileName, templateName)
+	}
+
+	tpl, err := core.template(templateName)
+	if err != nil {
+		return err
+	}
 
 	fullPath := core.OutputDir + "/" + fileName
 
-	err := CreateDir(filepath.Dir(fullPath))
+	err = CreateDir(filepath.Dir(fullPath))
 	if err != nil {
 		return err
 	}
@@ -509,6 +632,11 @@ func (core *App) SaveAsHTML(fileName, templateName string, data map[string]inter
 	}
 	defer f.Close()
 
+	if core.written == nil {
+		core.written = map[string]bool{}
+	}
+	core.written[fileName] = true
+
 	data["Site"] = map[string]interface{}{
 		"BaseURL":     core.SiteConfig.BaseURL,
 		"Title":       core.SiteConfig.Title,
@@ -517,7 +645,12 @@ func (core *App) SaveAsHTML(fileName, templateName string, data map[string]inter
 		"Keywords":    core.SiteConfig.Keywords,
 		"NowYear":     time.Now().Format("2006"),
 		"Timestamp":   time.Now().Unix(),
-		"Posts":       core.Posts,
+		"Posts":       listedPosts(core.Posts),
+		"Pages":       core.Pages,
+		"Sections":    core.Sections,
+		"Categories":  core.Categories,
+		"Collections": core.Collections,
+		"Menus":       core.menusFor(fileName),
 		"Tags":        core.Tags,
 	}
 
@@ -527,23 +660,27 @@ func (core *App) SaveAsHTML(fileName, templateName string, data map[string]inter
 func (core *App) CopyStaticFiles() {
 	var paths []string
 
-	filepath.Walk(core.SiteConfig.SourceDir+"/static", func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	// Статика тем копируется первой, чтобы одноимённые файлы сайта её перезаписали
+	dirs := core.staticDirs()
+	for i := len(dirs) - 1; i >= 0; i-- {
+		staticDir := dirs[i]
+		paths = paths[:0]
 
-		if !info.IsDir() {
-			paths = append(paths, path)
-		}
+		filepath.Walk(staticDir, func(path string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
 
-		return nil
-	})
+			if !info.IsDir() {
+				paths = append(paths, path)
+			}
 
-	if len(paths) > 0 {
+			return nil
+		})
 
 		for _, path := range paths {
 			dir := filepath.Dir(path)
-			destDir := strings.Replace(dir, core.SiteConfig.SourceDir+"/static", core.SiteConfig.BuildDir, 1)
+			destDir := strings.Replace(dir, staticDir, core.SiteConfig.BuildDir, 1)
 			filename := filepath.Base(path)
 
 			err := CreateDir(destDir)
@@ -568,15 +705,13 @@ func (core *App) CopyStaticFiles() {
 				log.Println(err)
 			}
 		}
-
 	}
 
 }
 
-func compileTemplate(templateName string, core *App) *template.Template {
-	t := template.New("")
-
-	funcMap := template.FuncMap{
+// templateFuncs - набор функций, доступных в шаблонах и шорткодах
+func (core *App) templateFuncs() template.FuncMap {
+	return template.FuncMap{
 		"safe_html": func(s string) template.HTML {
 			return template.HTML(s)
 		},
@@ -622,58 +757,70 @@ func compileTemplate(templateName string, core *App) *template.Template {
 		"mod": func(a, b int) int {
 			return a % b
 		},
-		"len": func(q []types.Post) int {
-			return len(q)
+		"truncate":       truncateFunc,
+		"truncate_words": truncateWords,
+		"markdownify":    core.markdownify,
+		"plainify":       plainify,
+		"replace":        replaceFunc,
+		"title":          titleCase,
+		"upper": func(s interface{}) string {
+			return strings.ToUpper(toString(s))
+		},
+		"lower": func(s interface{}) string {
+			return strings.ToLower(toString(s))
+		},
+		"capitalize": func(s interface{}) string {
+			return capitalize(toString(s))
+		},
+		"where":         where,
+		"sort":          sortBy,
+		"first":         first,
+		"last":          last,
+		"group_by":      groupBy,
+		"uniq":          uniq,
+		"shuffle":       shuffle,
+		"dict":          dict,
+		"list":          list,
+		"plural":        plural,
+		"number_format": numberFormat,
+		"absURL":        core.absURL,
+		"relURL":        core.relURL,
+		"date_format": func(layout string, t time.Time) string {
+			return formatDateRu(t.In(core.location()), layout)
+		},
+		"month_ru": func(t time.Time, grammaticalCase string) string {
+			return monthName(t.In(core.location()), grammaticalCase)
+		},
+		"image_set": func(src string) types.ImageSet {
+			set, err := core.imageProcessing().Process(src, "")
+			if err != nil {
+				log.Println(err)
+			}
+			return set
+		},
+		"image_url": func(src, preset string) string {
+			set, err := core.imageProcessing().Process(src, "")
+			if err != nil {
+				log.Println(err)
+			}
+			return set.Variant(preset).URL
+		},
+		"embed": core.makeEmbed,
+		"categories": func() []*types.Category {
+			return core.Categories
 		},
+		"category":    core.category,
+		"breadcrumbs": core.breadcrumbs,
 		"post_types": func(a string) string {
 
-			vv := core.SiteConfig.PostTypesValues
+			title := core.section(a).Title
 
-			if vv[a] != "" {
-				return vv[a]
+			if title != "" {
+				return title
 			} else {
 				return "-"
 			}
 
 		},
 	}
-
-	t = template.Must(t.Funcs(funcMap).ParseGlob(core.SiteConfig.SourceDir + "/layouts/*.html"))
-
-	return template.Must(t.ParseFiles(core.SiteConfig.SourceDir + "/" + templateName))
-}
-
-func DividePosts(posts types.Posts, perPage int, postType string) [][]types.Post {
-	var dividedPosts [][]types.Post
-	var allPosts []types.Post
-
-	if len(posts) > 0 {
-
-		for _, post := range posts {
-
-			if strings.HasPrefix(post.Type, postType) {
-				allPosts = append(allPosts, post)
-			} else {
-				if postType == "all" {
-					allPosts = append(allPosts, post)
-				} else if postType != "pages" && post.Type == postType {
-					allPosts = append(allPosts, post)
-				} else if post.Type == postType {
-					allPosts = append(allPosts, post)
-				}
-			}
-
-		}
-
-		for i := 0; i < len(allPosts); i += perPage {
-			end := i + perPage
-			if end > len(allPosts) {
-				end = len(allPosts)
-			}
-			dividedPosts = append(dividedPosts, allPosts[i:end])
-		}
-
-	}
-
-	return dividedPosts
 }
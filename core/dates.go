@@ -0,0 +1,146 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	_ "time/tzdata"
+)
+
+// dateLayouts - поддерживаемые форматы дат во front matter
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
+var monthsNominative = [...]string{
+	"январь", "февраль", "март", "апрель", "май", "июнь",
+	"июль", "август", "сентябрь", "октябрь", "ноябрь", "декабрь",
+}
+
+var monthsGenitive = [...]string{
+	"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря",
+}
+
+var monthsShort = [...]string{
+	"янв", "фев", "мар", "апр", "мая", "июн",
+	"июл", "авг", "сен", "окт", "ноя", "дек",
+}
+
+var weekdays = [...]string{
+	"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота",
+}
+
+var weekdaysShort = [...]string{
+	"вс", "пн", "вт", "ср", "чт", "пт", "сб",
+}
+
+// location - часовой пояс сайта (timezone: в конфиге), по умолчанию UTC
+func (core *App) location() *time.Location {
+	if core.loc == nil {
+		core.loc = time.UTC
+		if core.SiteConfig.Timezone != "" {
+			loc, err := time.LoadLocation(core.SiteConfig.Timezone)
+			if err != nil {
+				log.Println("timezone:", err, "- используется UTC")
+			} else {
+				core.loc = loc
+			}
+		}
+	}
+
+	return core.loc
+}
+
+// parseDate - разбор даты из front matter; даты без смещения считаются в часовом поясе сайта
+func parseDate(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t.In(loc), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("неизвестный формат даты %q", s)
+}
+
+// formatDateRu - форматирование даты по шаблону Go с русскими названиями месяцев и дней недели.
+// January после числа (2, 02, _2) выводится в родительном падеже: «2 января 2006», иначе - «январь 2006»
+func formatDateRu(t time.Time, layout string) string {
+	type token struct {
+		layout, placeholder, value string
+	}
+
+	month := int(t.Month()) - 1
+	weekday := int(t.Weekday())
+
+	longMonth := monthsNominative[month]
+	if i := strings.Index(layout, "January"); i > 0 && hasDayBefore(layout[:i]) {
+		longMonth = monthsGenitive[month]
+	}
+	if strings.HasPrefix(layout, "January") {
+		longMonth = capitalize(longMonth)
+	}
+
+	tokens := []token{
+		{"January", "\x00M\x00", longMonth},
+		{"Monday", "\x00W\x00", weekdays[weekday]},
+		{"Jan", "\x00m\x00", monthsShort[month]},
+		{"Mon", "\x00w\x00", weekdaysShort[weekday]},
+	}
+
+	for _, tok := range tokens {
+		layout = strings.ReplaceAll(layout, tok.layout, tok.placeholder)
+	}
+
+	result := t.Format(layout)
+
+	for _, tok := range tokens {
+		result = strings.ReplaceAll(result, tok.placeholder, tok.value)
+	}
+
+	return result
+}
+
+// hasDayBefore - в части шаблона есть число месяца
+func hasDayBefore(layout string) bool {
+	layout = strings.TrimRight(layout, " ")
+	return strings.HasSuffix(layout, "2") || strings.HasSuffix(layout, "02") || strings.HasSuffix(layout, "_2")
+}
+
+// monthName - название месяца в именительном ("nom") или родительном ("gen") падеже
+func monthName(t time.Time, grammaticalCase string) string {
+	if grammaticalCase == "gen" {
+		return monthsGenitive[t.Month()-1]
+	}
+
+	return monthsNominative[t.Month()-1]
+}
+
+// capitalize - первая буква заглавная
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r := []rune(s)
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		loc  *time.Location
+		want string
+	}{
+		{in: "2024-03-21", loc: moscow, want: "2024-03-21T00:00:00+03:00"},
+		{in: "2024-03-21", loc: time.UTC, want: "2024-03-21T00:00:00Z"},
+		{in: "2024-03-21 15:04", loc: moscow, want: "2024-03-21T15:04:00+03:00"},
+		{in: "2024-03-21T15:04:05", loc: moscow, want: "2024-03-21T15:04:05+03:00"},
+		{in: "21.03.2024", loc: moscow, want: "2024-03-21T00:00:00+03:00"},
+		{in: "21.03.2024 08:30", loc: time.UTC, want: "2024-03-21T08:30:00Z"},
+		// дата со смещением переводится в часовой пояс сайта
+		{in: "2024-03-21T00:21:04Z", loc: moscow, want: "2024-03-21T03:21:04+03:00"},
+		{in: "2024-03-21T10:00:00+05:00", loc: moscow, want: "2024-03-21T08:00:00+03:00"},
+		{in: "2024-03-21 10:00:00 -0700", loc: time.UTC, want: "2024-03-21T17:00:00Z"},
+		{in: "  2024-03-21  ", loc: time.UTC, want: "2024-03-21T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDate(tt.in, tt.loc)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if got.Format(time.RFC3339) != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.in, got.Format(time.RFC3339), tt.want)
+		}
+	}
+
+	if got, err := parseDate("", moscow); err != nil || !got.IsZero() {
+		t.Errorf("пустая дата: %v %v", got, err)
+	}
+	if _, err := parseDate("вчера", moscow); err == nil {
+		t.Error("ожидалась ошибка для неизвестного формата")
+	}
+}
+
+func TestFormatDateRu(t *testing.T) {
+	date := time.Date(2024, time.May, 2, 9, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		layout string
+		want   string
+	}{
+		{layout: "2 January 2006", want: "2 мая 2024"},
+		{layout: "02 January 2006, 15:04", want: "02 мая 2024, 09:05"},
+		{layout: "_2 January", want: " 2 мая"},
+		{layout: "January 2006", want: "Май 2024"},
+		{layout: "в January", want: "в май"},
+		{layout: "Monday, 2 Jan", want: "четверг, 2 мая"},
+		{layout: "Mon 02.01.2006", want: "чт 02.05.2024"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDateRu(date, tt.layout); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestMonthName(t *testing.T) {
+	date := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	if got := monthName(date, "nom"); got != "март" {
+		t.Errorf("nom: %q", got)
+	}
+	if got := monthName(date, "gen"); got != "марта" {
+		t.Errorf("gen: %q", got)
+	}
+}
@@ -0,0 +1,301 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EmbedConfig - настройки встраивания для провайдера (секция embeds: в конфиге). thumbnail - адрес
+// своей заглушки; для YouTube можно указать качество превью (hqdefault, maxresdefault): оно
+// загружается с i.ytimg.com при открытии страницы, до клика, поэтому по умолчанию выключено
+type EmbedConfig struct {
+	Direct    bool   `yaml:"direct"`
+	Domain    string `yaml:"domain"`
+	Params    string `yaml:"params"`
+	Thumbnail string `yaml:"thumbnail"`
+}
+
+// Embed - данные для шаблона встраивания
+type Embed struct {
+	Provider  string
+	ID        string
+	Src       string
+	Link      string
+	Thumbnail string
+	Title     string
+	Direct    bool
+}
+
+// defaultEmbeds - настройки провайдеров по умолчанию
+var defaultEmbeds = map[string]EmbedConfig{
+	"youtube":  {Domain: "www.youtube-nocookie.com"},
+	"rutube":   {Domain: "rutube.ru"},
+	"vk":       {Domain: "vk.com"},
+	"telegram": {Domain: "t.me"},
+}
+
+// builtinShortcodes - встроенные шорткоды встраивания; файлы source/shortcodes/<имя>.html их переопределяют
+const builtinShortcodes = `
+{{define "youtube.html"}}{{template "_embed.html" (embed "youtube" .)}}{{end}}
+{{define "rutube.html"}}{{template "_embed.html" (embed "rutube" .)}}{{end}}
+{{define "vk.html"}}{{template "_embed.html" (embed "vk" .)}}{{end}}
+{{define "telegram.html"}}{{template "_embed.html" (embed "telegram" .)}}{{end}}
+{{define "_embed.html"}}{{if .Direct}}
+<div class="lite-embed lite-embed-{{.Provider}} lite-embed-loaded"><iframe src="{{.Src}}" title="{{.Title}}" loading="lazy" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen></iframe></div>
+{{else}}
+<div class="lite-embed lite-embed-{{.Provider}}" data-src="{{.Src}}"{{with .Thumbnail}} data-thumbnail="{{.}}"{{end}}>
+<button type="button" class="lite-embed-play" aria-label="{{.Title}}"><span>{{.Title}}</span></button>
+<noscript><a href="{{.Link}}">{{.Title}}</a></noscript>
+</div>
+{{end}}{{end}}
+`
+
+var (
+	youtubeIDRe  = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	rutubeIDRe   = regexp.MustCompile(`^[a-f0-9]{32}$`)
+	vkIDRe       = regexp.MustCompile(`^(-?\d+)_(\d+)$`)
+	telegramIDRe = regexp.MustCompile(`^([A-Za-z0-9_]{4,})/(\d+)$`)
+)
+
+// embedConfig - настройки провайдера с учётом значений по умолчанию
+func (core *App) embedConfig(provider string) EmbedConfig {
+	cfg := defaultEmbeds[provider]
+
+	if custom, ok := core.SiteConfig.Embeds[provider]; ok {
+		cfg.Direct = custom.Direct
+		cfg.Params = custom.Params
+		if custom.Domain != "" {
+			cfg.Domain = custom.Domain
+		}
+		if custom.Thumbnail != "" {
+			cfg.Thumbnail = custom.Thumbnail
+		}
+	}
+
+	return cfg
+}
+
+// makeEmbed - разбор URL/ID видео или поста и сборка ссылок для встраивания без сетевых запросов
+func (core *App) makeEmbed(provider string, sc Shortcode) (Embed, error) {
+	cfg := core.embedConfig(provider)
+
+	source := sc.Get(0)
+	if source == "" {
+		source = sc.Get("id")
+	}
+	if source == "" {
+		source = sc.Get("url")
+	}
+	if source == "" {
+		return Embed{}, fmt.Errorf("%s: не указан URL или ID", provider)
+	}
+
+	e := Embed{
+		Provider:  provider,
+		Title:     sc.Get("title"),
+		Thumbnail: sc.Get("poster"),
+		Direct:    cfg.Direct,
+	}
+	if d := sc.Get("direct"); d != "" {
+		e.Direct, _ = strconv.ParseBool(d)
+	}
+
+	query := url.Values{}
+	if cfg.Params != "" {
+		if params, err := url.ParseQuery(cfg.Params); err == nil {
+			query = params
+		}
+	}
+
+	switch provider {
+	case "youtube":
+		id, start := parseYoutube(source)
+		if id == "" {
+			return Embed{}, fmt.Errorf("youtube: не удалось определить ID видео из %q", source)
+		}
+		if s := sc.Get("start"); s != "" {
+			start = s
+		}
+		if start != "" {
+			query.Set("start", start)
+		}
+		if !e.Direct {
+			query.Set("autoplay", "1")
+		}
+		e.ID = id
+		e.Src = embedURL("https://"+cfg.Domain+"/embed/"+id, query)
+		e.Link = "https://www.youtube.com/watch?v=" + id
+		if e.Thumbnail == "" && cfg.Thumbnail != "" && !strings.ContainsAny(cfg.Thumbnail, "/.") {
+			e.Thumbnail = "https://i.ytimg.com/vi/" + id + "/" + cfg.Thumbnail + ".jpg"
+		}
+		if e.Title == "" {
+			e.Title = "Смотреть видео на YouTube"
+		}
+	case "rutube":
+		id := parseRutube(source)
+		if id == "" {
+			return Embed{}, fmt.Errorf("rutube: не удалось определить ID видео из %q", source)
+		}
+		if !e.Direct {
+			query.Set("autoplay", "1")
+		}
+		e.ID = id
+		e.Src = embedURL("https://"+cfg.Domain+"/play/embed/"+id, query)
+		e.Link = "https://rutube.ru/video/" + id + "/"
+		if e.Title == "" {
+			e.Title = "Смотреть видео на RuTube"
+		}
+	case "vk":
+		oid, id, hash := parseVk(source)
+		if id == "" {
+			return Embed{}, fmt.Errorf("vk: не удалось определить ID видео из %q", source)
+		}
+		if h := sc.Get("hash"); h != "" {
+			hash = h
+		}
+		query.Set("oid", oid)
+		query.Set("id", id)
+		if hash != "" {
+			query.Set("hash", hash)
+		}
+		if !e.Direct {
+			query.Set("autoplay", "1")
+		}
+		e.ID = oid + "_" + id
+		e.Src = embedURL("https://"+cfg.Domain+"/video_ext.php", query)
+		e.Link = "https://vk.com/video" + e.ID
+		if e.Title == "" {
+			e.Title = "Смотреть видео VK"
+		}
+	case "telegram":
+		post := parseTelegram(source)
+		if post == "" {
+			return Embed{}, fmt.Errorf("telegram: не удалось определить пост из %q", source)
+		}
+		query.Set("embed", "1")
+		e.ID = post
+		e.Src = embedURL("https://"+cfg.Domain+"/"+post, query)
+		e.Link = "https://t.me/" + post
+		if e.Title == "" {
+			e.Title = "Показать пост из Telegram"
+		}
+	default:
+		return Embed{}, fmt.Errorf("неизвестный провайдер встраивания %q", provider)
+	}
+
+	if e.Thumbnail == "" && cfg.Thumbnail != "" {
+		e.Thumbnail = cfg.Thumbnail
+	}
+
+	return e, nil
+}
+
+// embedURL - сборка URL с параметрами
+func embedURL(base string, query url.Values) string {
+	if len(query) == 0 {
+		return base
+	}
+
+	return base + "?" + query.Encode()
+}
+
+// parseYoutube - ID и время начала из ссылки youtube.com/watch?v=, youtu.be/, /embed/, /shorts/ или ID
+func parseYoutube(s string) (string, string) {
+	if youtubeIDRe.MatchString(s) {
+		return s, ""
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", ""
+	}
+
+	start := strings.TrimSuffix(u.Query().Get("t"), "s")
+	if start == "" {
+		start = u.Query().Get("start")
+	}
+
+	if id := u.Query().Get("v"); youtubeIDRe.MatchString(id) {
+		return id, start
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if id := parts[len(parts)-1]; youtubeIDRe.MatchString(id) {
+		return id, start
+	}
+
+	return "", ""
+}
+
+// parseRutube - ID из ссылки rutube.ru/video/<id>/, /play/embed/<id> или ID
+func parseRutube(s string) string {
+	if rutubeIDRe.MatchString(s) {
+		return s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	for _, part := range strings.Split(u.Path, "/") {
+		if rutubeIDRe.MatchString(part) {
+			return part
+		}
+	}
+
+	return ""
+}
+
+// parseVk - владелец, ID и hash из ссылки vk.com/video-1_2, vkvideo.ru/video-1_2, video_ext.php или "-1_2"
+func parseVk(s string) (string, string, string) {
+	if m := vkIDRe.FindStringSubmatch(s); m != nil {
+		return m[1], m[2], ""
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", ""
+	}
+
+	q := u.Query()
+	if q.Get("oid") != "" && q.Get("id") != "" {
+		return q.Get("oid"), q.Get("id"), q.Get("hash")
+	}
+
+	if z := q.Get("z"); strings.HasPrefix(z, "video") {
+		if m := vkIDRe.FindStringSubmatch(strings.SplitN(strings.TrimPrefix(z, "video"), "/", 2)[0]); m != nil {
+			return m[1], m[2], ""
+		}
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	last := strings.TrimPrefix(parts[len(parts)-1], "video")
+	if m := vkIDRe.FindStringSubmatch(last); m != nil {
+		return m[1], m[2], ""
+	}
+
+	return "", "", ""
+}
+
+// parseTelegram - "канал/номер" из ссылки t.me/канал/номер или самой строки
+func parseTelegram(s string) string {
+	if telegramIDRe.MatchString(s) {
+		return s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	path := strings.TrimPrefix(strings.Trim(u.Path, "/"), "s/")
+	if telegramIDRe.MatchString(path) {
+		return path
+	}
+
+	return ""
+}
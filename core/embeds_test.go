@@ -0,0 +1,53 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMakeEmbedThumbnail(t *testing.T) {
+	tests := []struct {
+		name      string
+		thumbnail string
+		params    map[string]string
+		want      string
+	}{
+		{"без превью по умолчанию", "", nil, ""},
+		{"превью YouTube явно", "hqdefault", nil, "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg"},
+		{"своя картинка", "/images/video.jpg", nil, "/images/video.jpg"},
+		{"poster в шорткоде", "hqdefault", map[string]string{"poster": "/images/poster.jpg"}, "/images/poster.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := &App{SiteConfig: SiteConfig{Embeds: map[string]EmbedConfig{"youtube": {Thumbnail: tt.thumbnail}}}}
+			sc := Shortcode{Name: "youtube", Args: []string{"https://youtu.be/dQw4w9WgXcQ"}, Params: tt.params}
+
+			e, err := core.makeEmbed("youtube", sc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Thumbnail != tt.want {
+				t.Errorf("Thumbnail = %q, want %q", e.Thumbnail, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbedTemplateWithoutInlineCode(t *testing.T) {
+	out := executeTemplate(t, builtinShortcodes+`{{template "_embed.html" .}}`, Embed{
+		Provider:  "youtube",
+		Src:       "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?autoplay=1",
+		Thumbnail: "/images/video.jpg",
+		Title:     "Видео",
+	})
+
+	for _, bad := range []string{"onclick", "style="} {
+		if strings.Contains(out, bad) {
+			t.Errorf("в разметке есть %q: %s", bad, out)
+		}
+	}
+	if !strings.Contains(out, `data-thumbnail="/images/video.jpg"`) {
+		t.Errorf("нет data-thumbnail: %s", out)
+	}
+}
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BuildError - ошибка сборки с привязкой к файлу, строке и фрагментом исходника
+type BuildError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	Snippet []SnippetLine
+}
+
+// SnippetLine - строка фрагмента исходника вокруг ошибки
+type SnippetLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// snippetContext - сколько строк показывать до и после строки с ошибкой
+const snippetContext = 3
+
+var (
+	templateErrorRe = regexp.MustCompile(`(?s)^(?:html/)?template: ?([^:\s]+):(\d+)(?::(\d+))?: (.*)$`)
+	yamlLineRe      = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+)
+
+// Error - «файл:строка:колонка: сообщение»
+func (e BuildError) Error() string {
+	var b strings.Builder
+
+	if e.File != "" {
+		b.WriteString(e.File)
+		if e.Line > 0 {
+			fmt.Fprintf(&b, ":%d", e.Line)
+			if e.Column > 0 {
+				fmt.Fprintf(&b, ":%d", e.Column)
+			}
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+
+	return b.String()
+}
+
+// Report - текст ошибки вместе с фрагментом исходника для вывода в консоль
+func (e BuildError) Report() string {
+	var b strings.Builder
+	b.WriteString(e.Error())
+
+	for _, line := range e.Snippet {
+		marker := " "
+		if line.Current {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "\n  %s %4d | %s", marker, line.Number, line.Text)
+		if line.Current && e.Column > 0 {
+			fmt.Fprintf(&b, "\n         | %s^", strings.Repeat(" ", e.Column-1))
+		}
+	}
+
+	return b.String()
+}
+
+// newBuildError - ошибка с фрагментом исходника вокруг строки line
+func newBuildError(file string, line, column int, message string) BuildError {
+	e := BuildError{File: file, Line: line, Column: column, Message: message}
+
+	if file == "" || line <= 0 {
+		return e
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return e
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for n := line - snippetContext; n <= line+snippetContext; n++ {
+		if n < 1 || n > len(lines) {
+			continue
+		}
+		e.Snippet = append(e.Snippet, SnippetLine{
+			Number:  n,
+			Text:    strings.ReplaceAll(lines[n-1], "\t", "    "),
+			Current: n == line,
+		})
+	}
+
+	if column > 0 && line <= len(lines) {
+		// В сообщениях шаблонов колонка считается в байтах, во фрагменте табуляция заменена пробелами
+		prefix := lines[line-1]
+		if column-1 <= len(prefix) {
+			prefix = prefix[:column-1]
+		}
+		e.Column = len([]rune(strings.ReplaceAll(prefix, "\t", "    "))) + 1
+	}
+
+	return e
+}
+
+// templatePath - путь к файлу шаблона по его имени
+func (core *App) templatePath(name string) string {
+	for _, dir := range []string{"", "layouts", "shortcodes"} {
+		if path, ok := core.lookupFile(dir, name); ok {
+			return path
+		}
+	}
+
+	return name
+}
+
+// buildError - приведение ошибки к BuildError: для ошибок шаблонов определяются файл, строка и колонка,
+// для ошибок YAML во front matter - строка в файле контента
+func (core *App) buildError(file string, err error) BuildError {
+	var be BuildError
+	if errors.As(err, &be) {
+		return be
+	}
+
+	msg := err.Error()
+	if file != "" {
+		msg = strings.TrimPrefix(msg, file+": ")
+	}
+
+	if m := templateErrorRe.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[2])
+		column, _ := strconv.Atoi(m[3])
+		return newBuildError(core.templatePath(m[1]), line, column, m[4])
+	}
+
+	if m := yamlLineRe.FindStringSubmatch(msg); m != nil && file != "" {
+		line, _ := strconv.Atoi(m[1])
+		// Front matter начинается со второй строки файла, после «---»
+		return newBuildError(file, line+1, 0, "front matter: "+m[2])
+	}
+
+	return BuildError{File: file, Message: msg}
+}
+
+// contentError - ошибка в файле контента; если строка не известна, указывается строка
+// с ключом key во front matter
+func (core *App) contentError(file, key string, err error) {
+	be := core.buildError(file, err)
+
+	if be.Line == 0 && key != "" {
+		if data, rerr := os.ReadFile(file); rerr == nil {
+			for i, line := range strings.Split(string(data), "\n") {
+				if strings.HasPrefix(strings.TrimSpace(line), key+":") {
+					be = newBuildError(file, i+1, 0, be.Message)
+					break
+				}
+			}
+		}
+	}
+
+	core.addError(be)
+}
+
+// AddError - учёт ошибки сборки; сборка продолжается, все ошибки выводятся в консоль
+// и (в режиме serve) показываются в браузере
+func (core *App) AddError(err error) {
+	if err == nil {
+		return
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			core.AddError(e)
+		}
+		return
+	}
+
+	core.addError(core.buildError("", err))
+}
+
+// addError - добавление ошибки без повторов
+func (core *App) addError(be BuildError) {
+	for _, e := range core.Errors {
+		if e.Error() == be.Error() {
+			return
+		}
+	}
+
+	core.Errors = append(core.Errors, be)
+	log.Println(be.Report())
+}
+
+// errorOverlayTemplate - страница с ошибками сборки для режима serve; обновляется, пока ошибки не исправлены
+var errorOverlayTemplate = template.Must(template.New("overlay").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="2">
+<title>Ошибка сборки</title>
+<style>
+body{margin:0;padding:2rem;background:#1e1e24;color:#e8e8e8;font:15px/1.5 -apple-system,"Segoe UI",Roboto,sans-serif}
+h1{margin:0 0 1.5rem;color:#ff6b6b;font-size:1.4rem}
+.error{margin-bottom:2rem;border-left:4px solid #ff6b6b;padding-left:1rem}
+.file{color:#9cdcfe;font-family:monospace}
+.message{margin:.3rem 0 .7rem;white-space:pre-wrap}
+pre{margin:0;padding:.7rem 0;background:#2a2a32;border-radius:4px;overflow:auto;font-size:13px}
+pre span{display:block;padding:0 1rem}
+pre .current{background:#4a2a2e}
+pre i{display:inline-block;width:3.5em;color:#777;font-style:normal}
+footer{color:#888;font-size:13px}
+</style>
+</head>
+<body>
+<h1>Ошибок сборки: {{len .}}</h1>
+{{range .}}<div class="error">
+<div class="file">{{.File}}{{if .Line}}:{{.Line}}{{if .Column}}:{{.Column}}{{end}}{{end}}</div>
+<div class="message">{{.Message}}</div>
+{{if .Snippet}}<pre>{{range .Snippet}}<span{{if .Current}} class="current"{{end}}><i>{{.Number}}</i>{{.Text}}</span>{{end}}</pre>{{end}}
+</div>
+{{end}}<footer>Страница обновится автоматически после исправления.</footer>
+</body>
+</html>
+`))
+
+// ErrorOverlay - вывод страницы с ошибками сборки
+func ErrorOverlay(w io.Writer, errs []BuildError) error {
+	return errorOverlayTemplate.Execute(w, errs)
+}
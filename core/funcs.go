@@ -0,0 +1,689 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"math/rand"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ItemGroup - группа элементов коллекции с одинаковым значением поля (результат group_by)
+type ItemGroup struct {
+	Key   interface{}
+	Items interface{}
+}
+
+// toString - строковое значение аргумента шаблона
+func toString(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case template.HTML:
+		return string(s)
+	case fmt.Stringer:
+		return s.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toInt - целое значение аргумента шаблона
+func toInt(v interface{}) (int, error) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return int(rv.Float()), nil
+	case reflect.String:
+		return strconv.Atoi(strings.TrimSpace(rv.String()))
+	}
+
+	return 0, fmt.Errorf("не удалось привести %v к целому числу", v)
+}
+
+// toFloat - дробное значение аргумента шаблона
+func toFloat(v interface{}) (float64, error) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(strings.Replace(strings.TrimSpace(rv.String()), ",", ".", 1), 64)
+	}
+
+	return 0, fmt.Errorf("не удалось привести %v к числу", v)
+}
+
+// truncateFunc - обрезка до n символов: HTML (и строка с тегами, как .Summary) обрезается с закрытием
+// тегов, текст - по границе слова.
+// Использование: truncate 100 .Summary или truncate 100 "..." .Summary
+func truncateFunc(n int, args ...interface{}) (interface{}, error) {
+	ellipsis := "…"
+
+	switch len(args) {
+	case 1:
+	case 2:
+		ellipsis = toString(args[0])
+	default:
+		return nil, errors.New("truncate: ожидается длина, необязательный суффикс и текст")
+	}
+
+	s := args[len(args)-1]
+	if _, ok := s.(template.HTML); ok || hasMarkup(toString(s)) {
+		out, _ := truncateHTML(toString(s), 0, n, false, ellipsis)
+		return template.HTML(out), nil
+	}
+
+	return truncateText(toString(s), n, ellipsis), nil
+}
+
+// markupRe - HTML-тег или комментарий
+var markupRe = regexp.MustCompile(`<(/?[a-zA-Z][^<>]*|!--[\s\S]*?--)>`)
+
+// hasMarkup - в строке есть HTML-теги: .Summary и .Content - строки с готовым HTML, при обрезке
+// их нельзя экранировать как текст
+func hasMarkup(s string) bool {
+	return strings.IndexByte(s, '<') >= 0 && markupRe.MatchString(s)
+}
+
+// truncateWords - обрезка HTML или текста до n слов
+func truncateWords(n int, s interface{}) interface{} {
+	out, _ := truncateHTML(toString(s), n, 0, false, "…")
+
+	if _, ok := s.(template.HTML); ok || hasMarkup(toString(s)) {
+		return template.HTML(out)
+	}
+
+	return out
+}
+
+// markdownify - Markdown в HTML по правилам ссылок сайта; одиночный абзац разворачивается,
+// чтобы строку можно было вставить в заголовок
+func (core *App) markdownify(s interface{}) (template.HTML, error) {
+	out, err := core.markdownRender(toString(s), "", markdownContext(core.siteLinks(), ""))
+	if err != nil {
+		return "", err
+	}
+
+	out = strings.TrimSpace(out)
+	if strings.HasPrefix(out, "<p>") && strings.HasSuffix(out, "</p>") && strings.Count(out, "<p>") == 1 {
+		out = strings.TrimSuffix(strings.TrimPrefix(out, "<p>"), "</p>")
+	}
+
+	return template.HTML(out), nil
+}
+
+// plainify - текст без HTML-тегов, кода и встраиваний
+func plainify(s interface{}) string {
+	return removeHTMLTags(toString(s))
+}
+
+// replaceFunc - замена всех вхождений; строка последним аргументом для использования в конвейере
+func replaceFunc(old, new string, s interface{}) interface{} {
+	if h, ok := s.(template.HTML); ok {
+		return template.HTML(strings.ReplaceAll(string(h), old, new))
+	}
+
+	return strings.ReplaceAll(toString(s), old, new)
+}
+
+// titleCase - каждое слово с заглавной буквы
+func titleCase(s interface{}) string {
+	runes := []rune(toString(s))
+	start := true
+
+	for i, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start {
+				runes[i] = unicode.ToUpper(r)
+			}
+			start = false
+		} else {
+			start = r != '\''
+		}
+	}
+
+	return string(runes)
+}
+
+// dict - словарь из пар ключ-значение, например для передачи параметров в partial-шаблон
+func dict(values ...interface{}) (map[string]interface{}, error) {
+	if len(values)%2 != 0 {
+		return nil, errors.New("dict: ожидается чётное количество аргументов")
+	}
+
+	d := make(map[string]interface{}, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		key, ok := values[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: ключ %v не строка", values[i])
+		}
+		d[key] = values[i+1]
+	}
+
+	return d, nil
+}
+
+// list - срез из аргументов; встроенная функция шаблонов slice не переопределяется
+func list(values ...interface{}) []interface{} {
+	return values
+}
+
+// plural - форма слова для числа по правилам русского языка: plural 5 "статья" "статьи" "статей"
+func plural(n interface{}, one, few, many string) (string, error) {
+	i, err := toInt(n)
+	if err != nil {
+		return "", err
+	}
+
+	return pluralForm(i, one, few, many), nil
+}
+
+// pluralForm - выбор формы: 1, 21 - one; 2-4, 22-24 - few; 0, 5-20, 25 - many
+func pluralForm(n int, one, few, many string) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return one
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return few
+	default:
+		return many
+	}
+}
+
+// numberFormat - число с разделением разрядов неразрывным пробелом (начиная с пятизначных)
+// и запятой перед дробной частью: number_format 1234567.5 2 → «1 234 567,50»
+func numberFormat(v interface{}, decimals ...int) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+
+	prec := 0
+	if len(decimals) > 0 {
+		prec = decimals[0]
+	}
+
+	s := strconv.FormatFloat(math.Abs(f), 'f', prec, 64)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if f < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteString("−")
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 && len(intPart) > 4 {
+			b.WriteString("\u00a0")
+		}
+		b.WriteRune(r)
+	}
+	if fracPart != "" {
+		b.WriteString("," + fracPart)
+	}
+
+	return b.String(), nil
+}
+
+// absURL - абсолютный URL относительно baseURL сайта
+func (core *App) absURL(s interface{}) string {
+	path := toString(s)
+
+	if u, err := url.Parse(path); err == nil && (u.IsAbs() || strings.HasPrefix(path, "//")) {
+		return path
+	}
+
+	return strings.TrimRight(core.SiteConfig.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// relURL - URL от корня сайта с учётом пути в baseURL
+func (core *App) relURL(s interface{}) string {
+	path := toString(s)
+
+	if u, err := url.Parse(path); err == nil && (u.IsAbs() || strings.HasPrefix(path, "//")) {
+		return path
+	}
+
+	base := ""
+	if u, err := url.Parse(core.SiteConfig.BaseURL); err == nil {
+		base = strings.TrimRight(u.Path, "/")
+	}
+
+	return base + "/" + strings.TrimLeft(path, "/")
+}
+
+// indirect - значение по указателю или интерфейсу
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// sequence - коллекция (срез или массив) из аргумента шаблона
+func sequence(name string, collection interface{}) (reflect.Value, error) {
+	seq := indirect(reflect.ValueOf(collection))
+
+	if !seq.IsValid() {
+		return reflect.Value{}, fmt.Errorf("%s: пустая коллекция", name)
+	}
+	if seq.Kind() != reflect.Slice && seq.Kind() != reflect.Array {
+		return reflect.Value{}, fmt.Errorf("%s: ожидается срез, получено %s", name, seq.Type())
+	}
+
+	return seq, nil
+}
+
+// sliceType - тип результата для коллекции seq: именованный тип среза сохраняется
+// (types.Posts остаётся types.Posts с его методами), для массива - срез его элементов
+func sliceType(seq reflect.Value) reflect.Type {
+	if seq.Kind() == reflect.Slice {
+		return seq.Type()
+	}
+	return reflect.SliceOf(seq.Type().Elem())
+}
+
+// fieldValue - значение поля, метода без аргументов или ключа карты по пути вида "GitInfo.AuthorName"
+func fieldValue(item reflect.Value, path string) (reflect.Value, bool) {
+	if path == "" || path == "." {
+		return indirect(item), true
+	}
+
+	v := item
+	for _, name := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		if v.IsValid() && v.Kind() != reflect.Interface {
+			if m := v.MethodByName(name); m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() > 0 {
+				v = m.Call(nil)[0]
+				continue
+			}
+		}
+
+		v = indirect(v)
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			if m := v.MethodByName(name); m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() > 0 {
+				v = m.Call(nil)[0]
+				continue
+			}
+			f := v.FieldByName(name)
+			if !f.IsValid() || !f.CanInterface() {
+				return reflect.Value{}, false
+			}
+			v = f
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, false
+			}
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return reflect.Value{}, false
+			}
+		default:
+			return reflect.Value{}, false
+		}
+	}
+
+	return indirect(v), true
+}
+
+// compareValues - сравнение чисел, строк, дат и логических значений; второе значение - сравнимы ли они
+func compareValues(a, b reflect.Value) (int, bool) {
+	a, b = indirect(a), indirect(b)
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+
+	if ta, ok := a.Interface().(time.Time); ok {
+		if tb, ok := b.Interface().(time.Time); ok {
+			return ta.Compare(tb), true
+		}
+		return 0, false
+	}
+
+	if fa, ok := numberValue(a); ok {
+		if fb, ok := numberValue(b); ok {
+			switch {
+			case fa < fb:
+				return -1, true
+			case fa > fb:
+				return 1, true
+			}
+			return 0, true
+		}
+		return 0, false
+	}
+
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+
+	if a.Kind() == reflect.Bool && b.Kind() == reflect.Bool {
+		switch {
+		case a.Bool() == b.Bool():
+			return 0, true
+		case b.Bool():
+			return -1, true
+		}
+		return 1, true
+	}
+
+	return 0, false
+}
+
+// numberValue - числовое значение для сравнения
+func numberValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
+
+// equalValues - равенство значений с приведением чисел
+func equalValues(a, b reflect.Value) bool {
+	if c, ok := compareValues(a, b); ok {
+		return c == 0
+	}
+
+	a, b = indirect(a), indirect(b)
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// containsValue - есть ли значение в срезе, массиве или подстрока в строке
+func containsValue(seq, v reflect.Value) bool {
+	seq = indirect(seq)
+	if !seq.IsValid() {
+		return false
+	}
+
+	switch seq.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < seq.Len(); i++ {
+			if equalValues(seq.Index(i), v) {
+				return true
+			}
+		}
+	case reflect.String:
+		v = indirect(v)
+		return v.IsValid() && v.Kind() == reflect.String && strings.Contains(seq.String(), v.String())
+	}
+
+	return false
+}
+
+// matchValue - проверка значения поля оператором where
+func matchValue(field reflect.Value, op string, value reflect.Value) (bool, error) {
+	switch op {
+	case "=", "==", "eq":
+		return equalValues(field, value), nil
+	case "!=", "<>", "ne":
+		return !equalValues(field, value), nil
+	case ">", "gt", ">=", "ge", "<", "lt", "<=", "le":
+		c, ok := compareValues(field, value)
+		if !ok {
+			return false, nil
+		}
+		switch op {
+		case ">", "gt":
+			return c > 0, nil
+		case ">=", "ge":
+			return c >= 0, nil
+		case "<", "lt":
+			return c < 0, nil
+		}
+		return c <= 0, nil
+	case "in":
+		return containsValue(value, field), nil
+	case "not in":
+		return !containsValue(value, field), nil
+	case "contains":
+		return containsValue(field, value), nil
+	case "intersect":
+		field, value = indirect(field), indirect(value)
+		if !field.IsValid() || (field.Kind() != reflect.Slice && field.Kind() != reflect.Array) {
+			return false, nil
+		}
+		for i := 0; i < field.Len(); i++ {
+			if containsValue(value, field.Index(i)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return false, fmt.Errorf("where: неизвестный оператор %q", op)
+}
+
+// where - фильтр коллекции по полю: where .Posts "Type" "news", where .Posts "Tags" "contains" "go",
+// where .Posts "Date" ">=" $date. Операторы: = != > >= < <= in, not in, contains, intersect
+func where(collection interface{}, key string, args ...interface{}) (interface{}, error) {
+	seq, err := sequence("where", collection)
+	if err != nil {
+		return nil, err
+	}
+
+	op := "="
+	var value interface{}
+	switch len(args) {
+	case 1:
+		value = args[0]
+	case 2:
+		op, _ = args[0].(string)
+		value = args[1]
+	default:
+		return nil, errors.New("where: ожидается поле, необязательный оператор и значение")
+	}
+	op = strings.ToLower(strings.TrimSpace(op))
+
+	result := reflect.MakeSlice(sliceType(seq), 0, seq.Len())
+	for i := 0; i < seq.Len(); i++ {
+		field, ok := fieldValue(seq.Index(i), key)
+		if !ok {
+			continue
+		}
+		match, err := matchValue(field, op, reflect.ValueOf(value))
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			result = reflect.Append(result, seq.Index(i))
+		}
+	}
+
+	return result.Interface(), nil
+}
+
+// sortBy - сортировка коллекции по полю: sort .Posts "Title", sort .Posts "Date" "desc"; без поля - по значениям
+func sortBy(collection interface{}, args ...string) (interface{}, error) {
+	seq, err := sequence("sort", collection)
+	if err != nil {
+		return nil, err
+	}
+
+	key, order := "", "asc"
+	switch len(args) {
+	case 0:
+	case 1:
+		if a := strings.ToLower(args[0]); a == "asc" || a == "desc" {
+			order = a
+		} else {
+			key = args[0]
+		}
+	case 2:
+		key, order = args[0], strings.ToLower(args[1])
+	default:
+		return nil, errors.New("sort: ожидается поле и направление (asc, desc)")
+	}
+
+	result := reflect.MakeSlice(sliceType(seq), seq.Len(), seq.Len())
+	reflect.Copy(result, seq)
+
+	keys := make([]reflect.Value, result.Len())
+	for i := range keys {
+		keys[i], _ = fieldValue(result.Index(i), key)
+	}
+
+	idx := make([]int, len(keys))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		c, _ := compareValues(keys[idx[i]], keys[idx[j]])
+		if order == "desc" {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	sorted := reflect.MakeSlice(result.Type(), 0, result.Len())
+	for _, i := range idx {
+		sorted = reflect.Append(sorted, result.Index(i))
+	}
+
+	return sorted.Interface(), nil
+}
+
+// first - первые n элементов коллекции
+func first(n int, collection interface{}) (interface{}, error) {
+	seq, err := sequence("first", collection)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 0 {
+		n = 0
+	}
+	if n > seq.Len() {
+		n = seq.Len()
+	}
+
+	return seq.Slice(0, n).Interface(), nil
+}
+
+// last - последние n элементов коллекции
+func last(n int, collection interface{}) (interface{}, error) {
+	seq, err := sequence("last", collection)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 0 {
+		n = 0
+	}
+	if n > seq.Len() {
+		n = seq.Len()
+	}
+
+	return seq.Slice(seq.Len()-n, seq.Len()).Interface(), nil
+}
+
+// groupBy - группировка коллекции по значению поля в порядке первого появления: group_by "Type" .Posts
+func groupBy(key string, collection interface{}) ([]ItemGroup, error) {
+	seq, err := sequence("group_by", collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []reflect.Value
+	var items []reflect.Value
+
+	for i := 0; i < seq.Len(); i++ {
+		field, ok := fieldValue(seq.Index(i), key)
+		if !ok {
+			continue
+		}
+
+		pos := -1
+		for j, k := range keys {
+			if equalValues(k, field) {
+				pos = j
+				break
+			}
+		}
+		if pos < 0 {
+			keys = append(keys, field)
+			items = append(items, reflect.MakeSlice(sliceType(seq), 0, 1))
+			pos = len(keys) - 1
+		}
+		items[pos] = reflect.Append(items[pos], seq.Index(i))
+	}
+
+	groups := make([]ItemGroup, len(keys))
+	for i := range keys {
+		groups[i] = ItemGroup{Key: keys[i].Interface(), Items: items[i].Interface()}
+	}
+
+	return groups, nil
+}
+
+// uniq - коллекция без повторов с сохранением порядка
+func uniq(collection interface{}) (interface{}, error) {
+	seq, err := sequence("uniq", collection)
+	if err != nil {
+		return nil, err
+	}
+
+	result := reflect.MakeSlice(sliceType(seq), 0, seq.Len())
+	for i := 0; i < seq.Len(); i++ {
+		if !containsValue(result, seq.Index(i)) {
+			result = reflect.Append(result, seq.Index(i))
+		}
+	}
+
+	return result.Interface(), nil
+}
+
+// shuffle - коллекция в случайном порядке
+func shuffle(collection interface{}) (interface{}, error) {
+	seq, err := sequence("shuffle", collection)
+	if err != nil {
+		return nil, err
+	}
+
+	result := reflect.MakeSlice(sliceType(seq), seq.Len(), seq.Len())
+	reflect.Copy(result, seq)
+	swap := reflect.Swapper(result.Interface())
+	rand.Shuffle(result.Len(), swap)
+
+	return result.Interface(), nil
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"bytes"
+	"github.com/globalmac/boyar/types"
+	"html/template"
+	"testing"
+)
+
+// executeTemplate - выполнение шаблона с функциями сайта
+func executeTemplate(t *testing.T, src string, data interface{}) string {
+	t.Helper()
+
+	core := &App{}
+	tpl, err := template.New("").Funcs(core.templateFuncs()).Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+func TestTemplateFuncsKeepBuiltinSlice(t *testing.T) {
+	got := executeTemplate(t, `{{slice .Title 0 3}}|{{range list 1 "b"}}{{.}}{{end}}`, map[string]string{"Title": "Boyar"})
+	if want := "Boy|1b"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCollectionFuncsKeepSliceType(t *testing.T) {
+	posts := types.Posts{
+		{Title: "Б", Type: "news", Tags: []string{"go"}},
+		{Title: "А", Type: "news"},
+		{Title: "В", Type: "posts", Tags: []string{"go"}},
+	}
+
+	src := `{{range (where .Posts "Type" "news").FindByTag "go"}}{{.Title}}{{end}}|` +
+		`{{range (sort .Posts "Title").FindByTag "go"}}{{.Title}}{{end}}|` +
+		`{{len ((first 2 .Posts).FindByTag "go")}}{{len ((last 2 .Posts).FindByTag "go")}}|` +
+		`{{len ((uniq .Posts).FindByTag "go")}}{{len ((shuffle .Posts).FindByTag "go")}}`
+
+	got := executeTemplate(t, src, map[string]interface{}{"Posts": posts})
+	if want := "Б|БВ|11|22"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownifyLinkPolicy(t *testing.T) {
+	core := &App{SiteConfig: SiteConfig{
+		BaseURL:  "https://example.ru",
+		Markdown: DefaultMarkdownConfig(),
+		Links:    DefaultLinkPolicy(),
+	}}
+
+	got, err := core.markdownify("[свой](https://example.ru/about.html) и [чужой](https://go.dev)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<a href="https://example.ru/about.html">свой</a> и <a href="https://go.dev" target="_blank" rel="noopener noreferrer">чужой</a>`
+	if string(got) != want {
+		t.Errorf("\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestTruncateSummary(t *testing.T) {
+	post := types.Post{Summary: `<p>Привет <a href="/about.html">мир</a>, это длинный анонс</p>`}
+
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`{{truncate 100 .Summary}}`, `<p>Привет <a href="/about.html">мир</a>, это длинный анонс</p>`},
+		{`{{truncate 10 .Summary}}`, `<p>Привет <a href="/about.html">мир</a>…</p>`},
+		{`{{truncate 10 "..." .Summary}}`, `<p>Привет <a href="/about.html">мир</a>...</p>`},
+		{`{{truncate 10 .Title}}`, `a &lt; b и c…`},
+	}
+
+	for _, tt := range tests {
+		got := executeTemplate(t, tt.src, map[string]interface{}{"Summary": post.Summary, "Title": "a < b и c > d"})
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/globalmac/boyar/types"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitLogFormat - формат записи git log: хэш, короткий хэш, тема, автор, почта, даты автора и коммита
+const gitLogFormat = "%x1e%H%x1f%h%x1f%s%x1f%an%x1f%ae%x1f%aI%x1f%cI"
+
+// loadGitInfo - последний коммит для каждого файла в директории контента за один вызов git log.
+// Ключ карты - абсолютный путь к файлу
+func loadGitInfo(contentDir string) (map[string]types.GitInfo, error) {
+	absDir, err := filepath.Abs(contentDir)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := exec.Command("git", "-C", absDir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git: %s не в репозитории: %w", contentDir, err)
+	}
+	root := strings.TrimSpace(string(out))
+
+	out, err = exec.Command("git", "-C", root, "-c", "core.quotepath=false", "log",
+		"--name-only", "--no-merges", "--format="+gitLogFormat, "--", absDir).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+
+	info := map[string]types.GitInfo{}
+
+	for _, record := range bytes.Split(out, []byte{0x1e}) {
+		lines := strings.Split(strings.TrimSpace(string(record)), "\n")
+		if len(lines) == 0 || lines[0] == "" {
+			continue
+		}
+
+		fields := strings.Split(lines[0], "\x1f")
+		if len(fields) != 7 {
+			continue
+		}
+
+		gi := types.GitInfo{
+			Hash:            fields[0],
+			AbbreviatedHash: fields[1],
+			Subject:         fields[2],
+			AuthorName:      fields[3],
+			AuthorEmail:     fields[4],
+		}
+		gi.AuthorDate, _ = time.Parse(time.RFC3339, fields[5])
+		gi.CommitDate, _ = time.Parse(time.RFC3339, fields[6])
+
+		for _, name := range lines[1:] {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			path := filepath.Join(root, filepath.FromSlash(name))
+			if _, ok := info[path]; !ok {
+				info[path] = gi
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// postGitInfo - последний коммит файла поста
+func (core *App) postGitInfo(path string) (types.GitInfo, bool) {
+	if core.gitInfo == nil {
+		return types.GitInfo{}, false
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return types.GitInfo{}, false
+	}
+
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+
+	gi, ok := core.gitInfo[abs]
+
+	return gi, ok
+}
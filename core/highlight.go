@@ -0,0 +1,229 @@
+package core
+
+import (
+	"fmt"
+	"github.com/alecthomas/chroma/v2"
+	chromaHtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// HighlightConfig - настройки подсветки синтаксиса в блоках кода
+type HighlightConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	Style              string `yaml:"style"`
+	Classes            bool   `yaml:"classes"`
+	LineNumbers        bool   `yaml:"line_numbers"`
+	LineNumbersInTable bool   `yaml:"line_numbers_table"`
+	TabWidth           int    `yaml:"tab_width"`
+	GuessLanguage      bool   `yaml:"guess_language"`
+}
+
+// DefaultHighlightConfig - настройки подсветки по умолчанию
+func DefaultHighlightConfig() HighlightConfig {
+	return HighlightConfig{
+		Style:    "github",
+		Classes:  true,
+		TabWidth: 4,
+	}
+}
+
+// codeBlockOptions - атрибуты блока кода: ```go {title="main.go" linenos=table hl_lines="2-4 7"}
+type codeBlockOptions struct {
+	Language    string
+	Title       string
+	LineNumbers string
+	LineStart   int
+	HlLines     [][2]int
+}
+
+// codeBlockRenderer - рендер огороженных блоков кода с подсветкой через chroma
+type codeBlockRenderer struct {
+	config HighlightConfig
+}
+
+// RegisterFuncs - регистрация рендера для огороженных блоков кода
+func (r *codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *codeBlockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*ast.FencedCodeBlock)
+
+	var info string
+	if n.Info != nil {
+		info = string(n.Info.Segment.Value(source))
+	}
+	opts := parseCodeBlockInfo(info)
+
+	var code strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	err := r.highlight(w, code.String(), opts)
+	if err != nil {
+		return ast.WalkStop, err
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+// highlight - вывод подсвеченного блока кода с заголовком
+func (r *codeBlockRenderer) highlight(w io.Writer, code string, opts codeBlockOptions) error {
+	lexer := lexers.Get(opts.Language)
+	if lexer == nil && opts.Title != "" {
+		lexer = lexers.Match(opts.Title)
+	}
+	if lexer == nil && r.config.GuessLanguage {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return err
+	}
+
+	formatter := r.formatter(opts)
+
+	class := "highlight"
+	if opts.Language != "" {
+		class += " language-" + template.HTMLEscapeString(opts.Language)
+	}
+
+	fmt.Fprintf(w, "<div class=\"%s\">\n", class)
+	if opts.Title != "" {
+		fmt.Fprintf(w, "<div class=\"highlight-title\">%s</div>\n", template.HTMLEscapeString(opts.Title))
+	}
+
+	err = formatter.Format(w, styles.Get(r.config.Style), iterator)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n</div>\n")
+
+	return err
+}
+
+// formatter - HTML форматтер chroma с учётом атрибутов блока
+func (r *codeBlockRenderer) formatter(opts codeBlockOptions) *chromaHtml.Formatter {
+	lineNumbers := r.config.LineNumbers
+	inTable := r.config.LineNumbersInTable
+
+	switch opts.LineNumbers {
+	case "true":
+		lineNumbers = true
+	case "inline":
+		lineNumbers, inTable = true, false
+	case "table":
+		lineNumbers, inTable = true, true
+	case "false":
+		lineNumbers = false
+	}
+
+	options := []chromaHtml.Option{
+		chromaHtml.WithClasses(r.config.Classes),
+		chromaHtml.TabWidth(r.config.TabWidth),
+		chromaHtml.WithLineNumbers(lineNumbers),
+		chromaHtml.LineNumbersInTable(inTable),
+	}
+	if opts.LineStart > 0 {
+		options = append(options, chromaHtml.BaseLineNumber(opts.LineStart))
+	}
+	if len(opts.HlLines) > 0 {
+		options = append(options, chromaHtml.HighlightLines(opts.HlLines))
+	}
+
+	return chromaHtml.New(options...)
+}
+
+// parseCodeBlockInfo - разбор строки после ``` : язык и атрибуты в фигурных скобках
+func parseCodeBlockInfo(info string) codeBlockOptions {
+	var opts codeBlockOptions
+
+	info = strings.TrimSpace(info)
+	if info == "" {
+		return opts
+	}
+
+	if !strings.HasPrefix(info, "{") {
+		i := strings.IndexAny(info, " \t{")
+		if i < 0 {
+			opts.Language = info
+			return opts
+		}
+		opts.Language, info = info[:i], info[i:]
+	}
+
+	info = strings.TrimSpace(info)
+	info = strings.TrimPrefix(info, "{")
+	info = strings.TrimSuffix(info, "}")
+
+	params, _, err := parseShortcodeArgs(info)
+	if err != nil {
+		return opts
+	}
+
+	opts.Title = params["title"]
+	if opts.Title == "" {
+		opts.Title = params["filename"]
+	}
+	opts.LineNumbers = params["linenos"]
+	opts.LineStart, _ = strconv.Atoi(params["linenostart"])
+	opts.HlLines = parseLineRanges(params["hl_lines"])
+
+	return opts
+}
+
+// parseLineRanges - разбор диапазонов строк вида "2-4 7", "2-4,7", [2-4,7] или {2-4,7}; обратные диапазоны пропускаются
+func parseLineRanges(s string) [][2]int {
+	var ranges [][2]int
+
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '[' || r == ']' || r == '{' || r == '}' || r == '"'
+	}) {
+		from, to, found := strings.Cut(part, "-")
+
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			continue
+		}
+
+		end := start
+		if found {
+			end, err = strconv.Atoi(to)
+			if err != nil || end < start {
+				continue
+			}
+		}
+
+		ranges = append(ranges, [2]int{start, end})
+	}
+
+	return ranges
+}
+
+// HighlightCSS - запись CSS стилей темы подсветки
+func HighlightCSS(w io.Writer, cfg HighlightConfig) error {
+	formatter := chromaHtml.New(chromaHtml.WithClasses(true), chromaHtml.TabWidth(cfg.TabWidth))
+
+	return formatter.WriteCSS(w, styles.Get(cfg.Style))
+}
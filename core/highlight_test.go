@@ -0,0 +1,61 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCodeBlockInfo(t *testing.T) {
+	tests := []struct {
+		info string
+		want codeBlockOptions
+	}{
+		{"", codeBlockOptions{}},
+		{"   ", codeBlockOptions{}},
+		{"go", codeBlockOptions{Language: "go"}},
+		{"go {title=\"main.go\"}", codeBlockOptions{Language: "go", Title: "main.go"}},
+		{"go{linenos=table}", codeBlockOptions{Language: "go", LineNumbers: "table"}},
+		{"{title=\"без языка\"}", codeBlockOptions{Title: "без языка"}},
+		{"yaml {filename=config.yaml}", codeBlockOptions{Language: "yaml", Title: "config.yaml"}},
+		{"go {title=a.go filename=b.go}", codeBlockOptions{Language: "go", Title: "a.go"}},
+		{
+			"go {title=\"main.go\" linenos=inline linenostart=10 hl_lines=\"1,3-5\"}",
+			codeBlockOptions{Language: "go", Title: "main.go", LineNumbers: "inline", LineStart: 10, HlLines: [][2]int{{1, 1}, {3, 5}}},
+		},
+		{"go {hl_lines={1,3-5}}", codeBlockOptions{Language: "go", HlLines: [][2]int{{1, 1}, {3, 5}}}},
+		{"go {linenostart=abc}", codeBlockOptions{Language: "go"}},
+		{"go {title=\"без кавычки}", codeBlockOptions{Language: "go"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseCodeBlockInfo(tt.info); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCodeBlockInfo(%q) = %+v, want %+v", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestParseLineRanges(t *testing.T) {
+	tests := []struct {
+		s    string
+		want [][2]int
+	}{
+		{"", nil},
+		{"3", [][2]int{{3, 3}}},
+		{"1,3-5", [][2]int{{1, 1}, {3, 5}}},
+		{"{1,3-5}", [][2]int{{1, 1}, {3, 5}}},
+		{"[1, 3-5]", [][2]int{{1, 1}, {3, 5}}},
+		{"1 3-5 8", [][2]int{{1, 1}, {3, 5}, {8, 8}}},
+		{"4-4", [][2]int{{4, 4}}},
+		// обратный диапазон и нечисловые части пропускаются
+		{"5-3,7", [][2]int{{7, 7}}},
+		{"a,2-b,-3,6-", nil},
+		// строки за концом блока передаются как есть: chroma их не выделяет
+		{"100-200", [][2]int{{100, 200}}},
+	}
+
+	for _, tt := range tests {
+		if got := parseLineRanges(tt.s); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseLineRanges(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
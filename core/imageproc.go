@@ -0,0 +1,380 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/globalmac/boyar/types"
+	"golang.org/x/image/draw"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ImageProcessingConfig - обработка изображений при сборке (секция image_processing: в конфиге)
+type ImageProcessingConfig struct {
+	Enabled        bool                   `yaml:"enabled"`
+	Quality        int                    `yaml:"quality"`
+	PngCompression string                 `yaml:"png_compression"`
+	MaxWidth       int                    `yaml:"max_width"`
+	OutputDir      string                 `yaml:"output_dir"`
+	CacheDir       string                 `yaml:"cache_dir"`
+	Placeholder    bool                   `yaml:"placeholder"`
+	Sizes          string                 `yaml:"sizes"`
+	Presets        map[string]ImagePreset `yaml:"presets"`
+}
+
+// ImagePreset - пресет размера изображения
+type ImagePreset struct {
+	Width   int  `yaml:"width"`
+	Height  int  `yaml:"height"`
+	Quality int  `yaml:"quality"`
+	Crop    bool `yaml:"crop"`
+}
+
+// DefaultImageProcessingConfig - настройки обработки изображений по умолчанию (выключена)
+func DefaultImageProcessingConfig() ImageProcessingConfig {
+	return ImageProcessingConfig{
+		Quality:        82,
+		PngCompression: "default",
+		MaxWidth:       2000,
+		OutputDir:      "images",
+		CacheDir:       ".cache/images",
+		Placeholder:    true,
+		Sizes:          "100vw",
+	}
+}
+
+// defaultImagePresets - пресеты, если в конфиге не задано ни одного
+var defaultImagePresets = map[string]ImagePreset{
+	"small":  {Width: 480},
+	"medium": {Width: 960},
+	"large":  {Width: 1600},
+}
+
+// placeholderWidth - ширина размытой заглушки (LQIP)
+const placeholderWidth = 24
+
+// imageProcessor - обработка изображений с кэшем результатов на диске
+type imageProcessor struct {
+	config     ImageProcessingConfig
+	staticDirs []string
+	baseURL    string
+	outputDir  string
+	processed  map[string]types.ImageSet
+}
+
+// imageProcessing - обработчик изображений текущей сборки
+func (core *App) imageProcessing() *imageProcessor {
+	if core.images == nil {
+		// Пресеты по умолчанию копируются: общий конфиг и defaultImagePresets во время сборки не меняются
+		cfg := core.SiteConfig.ImageProcessing
+		if len(cfg.Presets) == 0 {
+			cfg.Presets = make(map[string]ImagePreset, len(defaultImagePresets))
+			for name, preset := range defaultImagePresets {
+				cfg.Presets[name] = preset
+			}
+		}
+
+		core.images = &imageProcessor{
+			config:     cfg,
+			staticDirs: core.staticDirs(),
+			baseURL:    core.SiteConfig.BaseURL,
+			outputDir:  core.OutputDir,
+			processed:  map[string]types.ImageSet{},
+		}
+	}
+
+	return core.images
+}
+
+// Process - создание вариантов изображения по пресетам. Для внешних и неподдерживаемых
+// изображений возвращается набор с исходным src
+func (p *imageProcessor) Process(src, sourcePath string) (types.ImageSet, error) {
+	set := types.ImageSet{Src: src}
+
+	if !p.config.Enabled || src == "" {
+		return set, nil
+	}
+
+	path := resolveLocalImage(src, sourcePath, p.staticDirs, p.baseURL)
+	if path == "" {
+		return set, nil
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+		return set, nil
+	}
+
+	if cached, ok := p.processed[path]; ok {
+		return cached, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return set, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return set, fmt.Errorf("%s: %w", path, err)
+	}
+
+	sum := sha1.Sum(data)
+	hash := hex.EncodeToString(sum[:])[:12]
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	var decoded image.Image
+	load := func() (image.Image, error) {
+		if decoded == nil {
+			img, _, err := image.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			decoded = img
+		}
+		return decoded, nil
+	}
+
+	names := make([]string, 0, len(p.config.Presets))
+	for name := range p.config.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		preset := p.config.Presets[name]
+		if preset.Width >= cfg.Width && !preset.Crop {
+			continue
+		}
+
+		v, err := p.variant(name, preset, base, hash, ext, cfg, load)
+		if err != nil {
+			return set, fmt.Errorf("%s: %w", path, err)
+		}
+		set.Variants = append(set.Variants, v)
+	}
+
+	full := ImagePreset{Width: cfg.Width}
+	if p.config.MaxWidth > 0 && p.config.MaxWidth < cfg.Width {
+		full.Width = p.config.MaxWidth
+	}
+	v, err := p.variant("full", full, base, hash, ext, cfg, load)
+	if err != nil {
+		return set, fmt.Errorf("%s: %w", path, err)
+	}
+	set.Src, set.Width, set.Height = v.URL, v.Width, v.Height
+	if !hasVariantURL(set.Variants, v.URL) {
+		set.Variants = append(set.Variants, v)
+	}
+
+	sort.SliceStable(set.Variants, func(i, j int) bool {
+		return set.Variants[i].Width < set.Variants[j].Width
+	})
+
+	if p.config.Placeholder {
+		set.Placeholder, err = p.placeholder(base, hash, cfg, load)
+		if err != nil {
+			return set, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	p.processed[path] = set
+
+	return set, nil
+}
+
+// variant - вариант изображения по пресету: берётся из кэша или создаётся и копируется в сборку
+func (p *imageProcessor) variant(name string, preset ImagePreset, base, hash, ext string, cfg image.Config, load func() (image.Image, error)) (types.ImageVariant, error) {
+	width, height := targetSize(preset, cfg.Width, cfg.Height)
+	quality := preset.Quality
+	if quality == 0 {
+		quality = p.config.Quality
+	}
+
+	fileName := fmt.Sprintf("%s_%s_%dx%d_q%d%s", base, hash, width, height, quality, ext)
+	if preset.Crop {
+		fileName = fmt.Sprintf("%s_%s_%dx%d_q%d_crop%s", base, hash, width, height, quality, ext)
+	}
+
+	cachePath := filepath.Join(p.config.CacheDir, fileName)
+	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
+		src, err := load()
+		if err != nil {
+			return types.ImageVariant{}, err
+		}
+
+		var buf bytes.Buffer
+		err = p.encode(&buf, resizeImage(src, preset, width, height), ext, quality)
+		if err != nil {
+			return types.ImageVariant{}, err
+		}
+
+		err = CreateDir(p.config.CacheDir)
+		if err != nil {
+			return types.ImageVariant{}, err
+		}
+
+		err = os.WriteFile(cachePath, buf.Bytes(), 0644)
+		if err != nil {
+			return types.ImageVariant{}, err
+		}
+	}
+
+	destPath := filepath.Join(p.outputDir, p.config.OutputDir, fileName)
+	if _, err := os.Stat(destPath); os.IsNotExist(err) {
+		err = copyFile(cachePath, destPath)
+		if err != nil {
+			return types.ImageVariant{}, err
+		}
+	}
+
+	return types.ImageVariant{
+		Name:   name,
+		URL:    "/" + strings.Trim(p.config.OutputDir, "/") + "/" + fileName,
+		Width:  width,
+		Height: height,
+		Crop:   preset.Crop,
+	}, nil
+}
+
+// placeholder - крошечная копия изображения в виде data URI
+func (p *imageProcessor) placeholder(base, hash string, cfg image.Config, load func() (image.Image, error)) (string, error) {
+	cachePath := filepath.Join(p.config.CacheDir, fmt.Sprintf("%s_%s_lqip.jpg", base, hash))
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		src, err := load()
+		if err != nil {
+			return "", err
+		}
+
+		preset := ImagePreset{Width: placeholderWidth}
+		width, height := targetSize(preset, cfg.Width, cfg.Height)
+
+		var buf bytes.Buffer
+		err = jpeg.Encode(&buf, resizeImage(src, preset, width, height), &jpeg.Options{Quality: 40})
+		if err != nil {
+			return "", err
+		}
+		data = buf.Bytes()
+
+		err = CreateDir(p.config.CacheDir)
+		if err != nil {
+			return "", err
+		}
+
+		err = os.WriteFile(cachePath, data, 0644)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// encode - кодирование изображения в исходный формат с настройками качества
+func (p *imageProcessor) encode(w io.Writer, img image.Image, ext string, quality int) error {
+	if ext == ".png" {
+		level := png.DefaultCompression
+		switch p.config.PngCompression {
+		case "none":
+			level = png.NoCompression
+		case "speed":
+			level = png.BestSpeed
+		case "best":
+			level = png.BestCompression
+		}
+
+		encoder := png.Encoder{CompressionLevel: level}
+
+		return encoder.Encode(w, img)
+	}
+
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+// targetSize - итоговые размеры по пресету с сохранением пропорций
+func targetSize(preset ImagePreset, width, height int) (int, int) {
+	switch {
+	case preset.Crop && preset.Width > 0 && preset.Height > 0:
+		return preset.Width, preset.Height
+	case preset.Width > 0 && preset.Width < width:
+		return preset.Width, max(1, height*preset.Width/width)
+	case preset.Width == 0 && preset.Height > 0 && preset.Height < height:
+		return max(1, width*preset.Height/height), preset.Height
+	}
+
+	return width, height
+}
+
+// resizeImage - масштабирование (и обрезка по центру для crop пресетов)
+func resizeImage(src image.Image, preset ImagePreset, width, height int) image.Image {
+	bounds := src.Bounds()
+
+	if preset.Crop && preset.Width > 0 && preset.Height > 0 {
+		ratio := float64(width) / float64(height)
+		w, h := bounds.Dx(), bounds.Dy()
+		if float64(w)/float64(h) > ratio {
+			cw := int(float64(h) * ratio)
+			x := bounds.Min.X + (w-cw)/2
+			bounds = image.Rect(x, bounds.Min.Y, x+cw, bounds.Max.Y)
+		} else {
+			ch := int(float64(w) / ratio)
+			y := bounds.Min.Y + (h-ch)/2
+			bounds = image.Rect(bounds.Min.X, y, bounds.Max.X, y+ch)
+		}
+	}
+
+	if bounds.Dx() == width && bounds.Dy() == height {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	return dst
+}
+
+// hasVariantURL - вариант с таким файлом уже есть в наборе
+func hasVariantURL(variants []types.ImageVariant, url string) bool {
+	for _, v := range variants {
+		if v.URL == url {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copyFile - копирование файла с созданием директорий
+func copyFile(src, dest string) error {
+	err := CreateDir(filepath.Dir(dest))
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+
+	return err
+}
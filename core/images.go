@@ -0,0 +1,264 @@
+package core
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ImagesConfig - обработка изображений в Markdown
+type ImagesConfig struct {
+	Lazy           bool `yaml:"lazy"`
+	Dimensions     bool `yaml:"dimensions"`
+	Figures        bool `yaml:"figures"`
+	WarnMissingAlt bool `yaml:"warn_missing_alt"`
+}
+
+// DefaultImagesConfig - настройки изображений по умолчанию
+func DefaultImagesConfig() ImagesConfig {
+	return ImagesConfig{
+		Lazy:           true,
+		Dimensions:     true,
+		Figures:        true,
+		WarnMissingAlt: true,
+	}
+}
+
+// markdownSourceKey - ключ контекста парсера с путём к файлу поста
+var markdownSourceKey = parser.NewContextKey()
+
+// KindFigure - узел <figure> для изображения с подписью
+var KindFigure = ast.NewNodeKind("Figure")
+
+// Figure - блок с изображением и подписью из title
+type Figure struct {
+	ast.BaseBlock
+	Caption []byte
+}
+
+func (n *Figure) Kind() ast.NodeKind {
+	return KindFigure
+}
+
+func (n *Figure) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Caption": string(n.Caption)}, nil)
+}
+
+// imageSize - размеры изображения в пикселях
+type imageSize struct {
+	Width, Height int
+}
+
+// ImageTransformer - размеры, ленивая загрузка и <figure> для изображений
+type ImageTransformer struct {
+	Config     ImagesConfig
+	StaticDirs []string
+	BaseURL    string
+	Processor  *imageProcessor
+
+	sizes  map[string]imageSize
+	warned map[string]bool
+}
+
+// warnMissingAlt - предупреждение об изображении без alt один раз на файл: Markdown поста
+// рендерится несколько раз (текст, анонс, markdownify)
+func (t *ImageTransformer) warnMissingAlt(sourcePath, dest string) {
+	if t.warned == nil {
+		t.warned = map[string]bool{}
+	}
+
+	key := sourcePath + "\x00" + dest
+	if t.warned[key] {
+		return
+	}
+	t.warned[key] = true
+
+	log.Printf("%s: изображение %s без alt текста\n", sourcePath, dest)
+}
+
+// Transform - обработка изображений документа
+func (t *ImageTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	sourcePath, _ := pc.Get(markdownSourceKey).(string)
+	source := reader.Source()
+
+	var figures []*ast.Image
+
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		img, ok := n.(*ast.Image)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		if t.Config.WarnMissingAlt && strings.TrimSpace(string(img.Text(source))) == "" {
+			t.warnMissingAlt(sourcePath, string(img.Destination))
+		}
+
+		processed := false
+		if t.Processor != nil && t.Processor.config.Enabled {
+			processed = t.applyImageSet(img, sourcePath)
+		}
+
+		if t.Config.Dimensions && !processed {
+			if size, ok := t.size(string(img.Destination), sourcePath); ok {
+				img.SetAttributeString("width", []byte(strconv.Itoa(size.Width)))
+				img.SetAttributeString("height", []byte(strconv.Itoa(size.Height)))
+			}
+		}
+
+		if t.Config.Lazy {
+			img.SetAttributeString("loading", []byte("lazy"))
+			img.SetAttributeString("decoding", []byte("async"))
+		}
+
+		if t.Config.Figures && len(img.Title) > 0 && isOnlyChild(img) {
+			figures = append(figures, img)
+		}
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	for _, img := range figures {
+		paragraph := img.Parent()
+		figure := &Figure{Caption: img.Title}
+		img.Title = nil
+
+		paragraph.Parent().ReplaceChild(paragraph.Parent(), paragraph, figure)
+		figure.AppendChild(figure, img)
+	}
+}
+
+// isOnlyChild - изображение единственное в абзаце (можно обернуть в <figure>)
+func isOnlyChild(img *ast.Image) bool {
+	parent := img.Parent()
+	if parent == nil || parent.Kind() != ast.KindParagraph || parent.Parent() == nil {
+		return false
+	}
+
+	return parent.FirstChild() == img && parent.LastChild() == img
+}
+
+// applyImageSet - подмена изображения обработанной версией с srcset и заглушкой
+func (t *ImageTransformer) applyImageSet(img *ast.Image, sourcePath string) bool {
+	set, err := t.Processor.Process(string(img.Destination), sourcePath)
+	if err != nil {
+		log.Printf("%s: %s\n", sourcePath, err)
+		return false
+	}
+	if !set.IsProcessed() {
+		return false
+	}
+
+	img.Destination = []byte(set.Src)
+	img.SetAttributeString("srcset", []byte(set.Srcset()))
+	img.SetAttributeString("sizes", []byte(t.Processor.config.Sizes))
+
+	if t.Config.Dimensions {
+		img.SetAttributeString("width", []byte(strconv.Itoa(set.Width)))
+		img.SetAttributeString("height", []byte(strconv.Itoa(set.Height)))
+	}
+
+	// Заглушка в data-атрибуте, а не в style: инлайн-стили блокируются строгой CSP.
+	// Фон из data-placeholder ставит static/js/app.js
+	if set.Placeholder != "" {
+		img.SetAttributeString("data-placeholder", []byte(set.Placeholder))
+	}
+
+	return true
+}
+
+// size - размеры локального изображения (результат кэшируется на время сборки)
+func (t *ImageTransformer) size(src, sourcePath string) (imageSize, bool) {
+	path := resolveLocalImage(src, sourcePath, t.StaticDirs, t.BaseURL)
+	if path == "" {
+		return imageSize{}, false
+	}
+
+	if t.sizes == nil {
+		t.sizes = map[string]imageSize{}
+	}
+	if size, ok := t.sizes[path]; ok {
+		return size, size.Width > 0
+	}
+
+	var size imageSize
+	if f, err := os.Open(path); err == nil {
+		if cfg, _, err := image.DecodeConfig(f); err == nil {
+			size = imageSize{cfg.Width, cfg.Height}
+		}
+		f.Close()
+	}
+	t.sizes[path] = size
+
+	return size, size.Width > 0
+}
+
+// resolveLocalImage - путь к файлу изображения на диске: /img.png ищется в static сайта и тем,
+// относительные пути - рядом с файлом поста и в static
+func resolveLocalImage(src, sourcePath string, staticDirs []string, baseURL string) string {
+	if baseURL != "" && strings.HasPrefix(src, baseURL) {
+		src = strings.TrimPrefix(src, baseURL)
+	}
+
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return ""
+	}
+
+	p, err := url.PathUnescape(u.Path)
+	if err != nil {
+		return ""
+	}
+
+	var candidates []string
+	if !strings.HasPrefix(p, "/") && sourcePath != "" {
+		candidates = append(candidates, filepath.Join(filepath.Dir(sourcePath), p))
+	}
+	for _, dir := range staticDirs {
+		candidates = append(candidates, filepath.Join(dir, p))
+	}
+
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && !info.IsDir() {
+			return c
+		}
+	}
+
+	return ""
+}
+
+// figureRenderer - вывод узла Figure
+type figureRenderer struct{}
+
+func (r *figureRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindFigure, r.renderFigure)
+}
+
+func (r *figureRenderer) renderFigure(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*Figure)
+
+	if entering {
+		_, _ = w.WriteString("<figure>\n")
+	} else {
+		_, _ = w.WriteString("\n<figcaption>")
+		_, _ = w.Write(util.EscapeHTML(n.Caption))
+		_, _ = w.WriteString("</figcaption>\n</figure>\n")
+	}
+
+	return ast.WalkContinue, nil
+}
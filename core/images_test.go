@@ -0,0 +1,57 @@
+package core
+
+import (
+	"bytes"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/util"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestImageTransformerWarnsMissingAltOnce(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	md := goldmark.New(goldmark.WithParserOptions(parser.WithASTTransformers(
+		util.Prioritized(&ImageTransformer{Config: ImagesConfig{WarnMissingAlt: true}}, 8000),
+	)))
+
+	render := func(source, markdown string) {
+		pc := parser.NewContext()
+		pc.Set(markdownSourceKey, source)
+		if err := md.Convert([]byte(markdown), io.Discard, parser.WithContext(pc)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// текст и анонс одного поста, затем другой пост с тем же изображением
+	render("content/a.md", "![](/img/1.png) ![Есть alt](/img/2.png)")
+	render("content/a.md", "![](/img/1.png)")
+	render("content/b.md", "![](/img/1.png)")
+
+	if got := strings.Count(logs.String(), "без alt"); got != 2 {
+		t.Errorf("предупреждений: %d, ожидалось 2\n%s", got, logs.String())
+	}
+}
+
+func TestImageProcessingKeepsConfig(t *testing.T) {
+	core := &App{}
+	p := core.imageProcessing()
+
+	if len(p.config.Presets) != len(defaultImagePresets) {
+		t.Fatalf("пресетов: %d", len(p.config.Presets))
+	}
+	if core.SiteConfig.ImageProcessing.Presets != nil {
+		t.Error("пресеты по умолчанию записаны в SiteConfig")
+	}
+
+	p.config.Presets["tmp"] = ImagePreset{Width: 1}
+	if _, ok := defaultImagePresets["tmp"]; ok {
+		t.Error("изменение пресетов сборки меняет defaultImagePresets")
+	}
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"bytes"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"gopkg.in/yaml.v3"
+	"net/url"
+	"strings"
+)
+
+// LinkPolicy - правила оформления внешних ссылок (секция links: в конфиге и во front matter поста)
+type LinkPolicy struct {
+	TargetBlank bool     `yaml:"target_blank"`
+	Noopener    bool     `yaml:"noopener"`
+	Nofollow    bool     `yaml:"nofollow"`
+	Ugc         bool     `yaml:"ugc"`
+	Internal    []string `yaml:"internal"`
+	Allow       []string `yaml:"allow"`
+	Deny        []string `yaml:"deny"`
+	Class       string   `yaml:"class"`
+	Icon        string   `yaml:"icon"`
+
+	host string
+}
+
+// linkPolicyKey - ключ контекста парсера с правилами ссылок текущего поста
+var linkPolicyKey = parser.NewContextKey()
+
+// DefaultLinkPolicy - правила по умолчанию: внешние ссылки в новом окне с rel="noopener noreferrer"
+func DefaultLinkPolicy() LinkPolicy {
+	return LinkPolicy{
+		TargetBlank: true,
+		Noopener:    true,
+	}
+}
+
+// ForPost - правила с учётом переопределений из front matter поста
+func (p LinkPolicy) ForPost(baseURL string, override yaml.Node) (LinkPolicy, error) {
+	if u, err := url.Parse(baseURL); err == nil {
+		p.host = strings.ToLower(u.Hostname())
+	}
+
+	if !override.IsZero() {
+		if err := override.Decode(&p); err != nil {
+			return p, err
+		}
+	}
+
+	return p, nil
+}
+
+// siteLinks - правила ссылок сайта без переопределений поста (для markdownify)
+func (core *App) siteLinks() LinkPolicy {
+	links, _ := core.SiteConfig.Links.ForPost(core.SiteConfig.BaseURL, yaml.Node{})
+	return links
+}
+
+// linkHost - хост внешней ссылки; пустая строка для относительных и прочих ссылок
+func linkHost(dest []byte) string {
+	lower := bytes.ToLower(dest)
+	if !bytes.HasPrefix(lower, []byte("http://")) && !bytes.HasPrefix(lower, []byte("https://")) && !bytes.HasPrefix(lower, []byte("//")) {
+		return ""
+	}
+
+	u, err := url.Parse(string(dest))
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+// matchDomain - совпадение хоста с доменом из списка (включая поддомены)
+func matchDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "."))
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsExternal - ссылка ведёт на другой сайт (не BaseURL и не домены из internal)
+func (p LinkPolicy) IsExternal(host string) bool {
+	if host == "" {
+		return false
+	}
+
+	h := strings.TrimPrefix(host, "www.")
+	if p.host != "" && h == strings.TrimPrefix(p.host, "www.") {
+		return false
+	}
+
+	return !matchDomain(host, p.Internal)
+}
+
+// rel - значение атрибута rel для внешнего хоста
+func (p LinkPolicy) rel(host string) string {
+	var rel []string
+
+	if p.Noopener {
+		rel = append(rel, "noopener", "noreferrer")
+	}
+
+	denied := matchDomain(host, p.Deny)
+	allowed := matchDomain(host, p.Allow) && !denied
+
+	if (p.Nofollow || denied) && !allowed {
+		rel = append(rel, "nofollow")
+	}
+	if (p.Ugc || denied) && !allowed {
+		rel = append(rel, "ugc")
+	}
+
+	return strings.Join(rel, " ")
+}
+
+// apply - оформление внешней ссылки по правилам
+func (p LinkPolicy) apply(n ast.Node, dest []byte) {
+	host := linkHost(dest)
+	if !p.IsExternal(host) {
+		return
+	}
+
+	if p.TargetBlank {
+		n.SetAttributeString("target", []byte("_blank"))
+	}
+	if rel := p.rel(host); rel != "" {
+		n.SetAttributeString("rel", []byte(rel))
+	}
+	if p.Class != "" {
+		class := p.Class
+		if v, ok := n.AttributeString("class"); ok {
+			if b, ok := v.([]byte); ok && len(b) > 0 {
+				class = string(b) + " " + class
+			}
+		}
+		n.SetAttributeString("class", []byte(class))
+	}
+
+	if p.Icon != "" {
+		icon := ast.NewString([]byte(p.Icon))
+		icon.SetCode(true)
+		n.AppendChild(n, icon)
+	}
+}
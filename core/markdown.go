@@ -0,0 +1,212 @@
+package core
+
+import (
+	"bytes"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// MarkdownConfig - настройки рендера Markdown (секция markdown: в конфиге). typographer - английские
+// кавычки goldmark, russian_typography - русский типограф; если включены оба, применяется русский
+type MarkdownConfig struct {
+	Table             bool `yaml:"table"`
+	Strikethrough     bool `yaml:"strikethrough"`
+	Linkify           bool `yaml:"linkify"`
+	Footnote          bool `yaml:"footnote"`
+	TaskList          bool `yaml:"task_list"`
+	Typographer       bool `yaml:"typographer"`
+	RussianTypography bool `yaml:"russian_typography"`
+	DefinitionList    bool `yaml:"definition_list"`
+	Attribute         bool `yaml:"attribute"`
+	HardWraps         bool `yaml:"hard_wraps"`
+	Unsafe            bool `yaml:"unsafe"`
+
+	Highlight HighlightConfig `yaml:"highlight"`
+	Images    ImagesConfig    `yaml:"images"`
+
+	// PostTypes - переопределения настроек для отдельных типов постов,
+	// не указанные ключи наследуются от общей секции
+	PostTypes map[string]MarkdownConfig `yaml:"-"`
+}
+
+// DefaultMarkdownConfig - настройки рендера по умолчанию
+func DefaultMarkdownConfig() MarkdownConfig {
+	return MarkdownConfig{
+		Table:         true,
+		Strikethrough: true,
+		Linkify:       true,
+		Footnote:      true,
+		TaskList:      true,
+		HardWraps:     true,
+		Unsafe:        true,
+		Highlight:     DefaultHighlightConfig(),
+		Images:        DefaultImagesConfig(),
+	}
+}
+
+// UnmarshalYAML - разбор секции с наследованием настроек в post_types
+func (m *MarkdownConfig) UnmarshalYAML(value *yaml.Node) error {
+	type options MarkdownConfig
+
+	opts := options(*m)
+	if err := value.Decode(&opts); err != nil {
+		return err
+	}
+
+	var raw struct {
+		PostTypes map[string]yaml.Node `yaml:"post_types"`
+	}
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	*m = MarkdownConfig(opts)
+	m.PostTypes = nil
+
+	if len(raw.PostTypes) > 0 {
+		m.PostTypes = make(map[string]MarkdownConfig, len(raw.PostTypes))
+		for postType, node := range raw.PostTypes {
+			override := *m
+			override.PostTypes = nil
+			if err := node.Decode(&override); err != nil {
+				return err
+			}
+			m.PostTypes[postType] = override
+		}
+	}
+
+	return nil
+}
+
+// ForPostType - настройки для типа поста: ищется ближайший родительский тип с переопределением
+func (m MarkdownConfig) ForPostType(postType string) (MarkdownConfig, string) {
+	for postType != "" {
+		if cfg, ok := m.PostTypes[postType]; ok {
+			return cfg, postType
+		}
+
+		i := strings.LastIndex(postType, "/")
+		if i < 0 {
+			break
+		}
+		postType = postType[:i]
+	}
+
+	return m, ""
+}
+
+// newMarkdown - сборка движка goldmark по настройкам
+func (core *App) newMarkdown(cfg MarkdownConfig) goldmark.Markdown {
+	var extensions []goldmark.Extender
+	if cfg.Table {
+		extensions = append(extensions, extension.Table)
+	}
+	if cfg.Strikethrough {
+		extensions = append(extensions, extension.Strikethrough)
+	}
+	if cfg.Linkify {
+		extensions = append(extensions, extension.Linkify)
+	}
+	if cfg.Footnote {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if cfg.TaskList {
+		extensions = append(extensions, extension.TaskList)
+	}
+	if cfg.Typographer && !cfg.RussianTypography {
+		extensions = append(extensions, extension.Typographer)
+	}
+	if cfg.DefinitionList {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+
+	parserOptions := []parser.Option{
+		parser.WithAutoHeadingID(),
+		parser.WithASTTransformers(
+			util.Prioritized(&ASTTransformer{}, 10000),
+			util.Prioritized(&ImageTransformer{
+				Config:     cfg.Images,
+				StaticDirs: core.staticDirs(),
+				BaseURL:    core.SiteConfig.BaseURL,
+				Processor:  core.imageProcessing(),
+			}, 8000),
+		),
+	}
+	if cfg.RussianTypography {
+		parserOptions = append(parserOptions, parser.WithASTTransformers(
+			util.Prioritized(&TypographTransformer{}, 9000),
+		))
+	}
+	if cfg.Attribute {
+		parserOptions = append(parserOptions, parser.WithAttribute())
+	}
+
+	rendererOptions := []renderer.Option{
+		renderer.WithNodeRenderers(util.Prioritized(&figureRenderer{}, 500)),
+	}
+	if cfg.HardWraps {
+		rendererOptions = append(rendererOptions, html.WithHardWraps())
+	}
+	if cfg.Unsafe {
+		rendererOptions = append(rendererOptions, html.WithUnsafe())
+	}
+	if cfg.Highlight.Enabled {
+		rendererOptions = append(rendererOptions, renderer.WithNodeRenderers(
+			util.Prioritized(&codeBlockRenderer{config: cfg.Highlight}, 100),
+		))
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(rendererOptions...),
+	)
+}
+
+// markdownEngine - движок для типа поста, создаётся один раз за сборку
+func (core *App) markdownEngine(postType string) goldmark.Markdown {
+	cfg, key := core.SiteConfig.Markdown.ForPostType(postType)
+
+	if core.markdown == nil {
+		core.markdown = map[string]goldmark.Markdown{}
+	}
+
+	md, ok := core.markdown[key]
+	if !ok {
+		md = core.newMarkdown(cfg)
+		core.markdown[key] = md
+	}
+
+	return md
+}
+
+// markdownContext - новый контекст парсера с правилами ссылок и путём к исходному файлу
+func markdownContext(links LinkPolicy, source string) parser.Context {
+	pc := parser.NewContext()
+	pc.Set(linkPolicyKey, links)
+	pc.Set(markdownSourceKey, source)
+
+	return pc
+}
+
+// markdownRender - рендер Markdown; pc передаёт в трансформеры настройки конкретного поста
+func (core *App) markdownRender(markdown, postType string, pc parser.Context) (string, error) {
+	var buf bytes.Buffer
+
+	if pc == nil {
+		pc = parser.NewContext()
+	}
+
+	err := core.markdownEngine(postType).Convert([]byte(markdown), &buf, parser.WithContext(pc))
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
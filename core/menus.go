@@ -0,0 +1,161 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"log"
+	"sort"
+	"strings"
+)
+
+// menuPath - путь адреса для сравнения с текущей страницей: без BaseURL, query, #якоря и index.html
+func (core *App) menuPath(url string) string {
+	url = strings.TrimPrefix(url, core.SiteConfig.BaseURL)
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	url = strings.TrimSuffix(url, "index.html")
+
+	return "/" + strings.TrimLeft(url, "/")
+}
+
+// menuURL - адрес пункта меню: к внутренним путям добавляется BaseURL, внешние ссылки не меняются
+func (core *App) menuURL(url string) string {
+	if strings.HasPrefix(url, "/") && !strings.HasPrefix(url, "//") {
+		return core.SiteConfig.BaseURL + url
+	}
+	return url
+}
+
+// buildMenus - меню из секции menus: конфига и ключа menu: во front matter страниц и постов.
+// Пункт из front matter без name и url получает заголовок и адрес страницы
+func (core *App) buildMenus() {
+	entries := map[string][]types.MenuEntry{}
+
+	for name, items := range core.SiteConfig.Menus {
+		entries[name] = append(entries[name], items...)
+	}
+
+	for _, post := range append(append(types.Posts{}, core.Pages...), listedPosts(core.Posts)...) {
+		for name, entry := range post.Menus {
+			if entry.Name == "" {
+				entry.Name = post.Title
+			}
+			if entry.URL == "" {
+				entry.URL = post.Permarlink()
+			}
+			entries[name] = append(entries[name], entry)
+		}
+	}
+
+	core.Menus = map[string]types.Menu{}
+
+	for name, list := range entries {
+		core.Menus[name] = core.menuTree(name, list)
+	}
+}
+
+// menuTree - дерево пунктов меню по parent; пункт с неизвестным родителем или в цикле родителей
+// остаётся на верхнем уровне
+func (core *App) menuTree(name string, entries []types.MenuEntry) types.Menu {
+	items := map[string]*types.MenuItem{}
+	all := make([]*types.MenuItem, 0, len(entries))
+
+	for _, entry := range entries {
+		entry.URL = core.menuURL(entry.URL)
+		item := &types.MenuItem{MenuEntry: entry}
+		all = append(all, item)
+		if _, ok := items[entry.Key()]; !ok {
+			items[entry.Key()] = item
+		}
+	}
+
+	parents := map[*types.MenuItem]*types.MenuItem{}
+	for _, item := range all {
+		if item.Parent == "" {
+			continue
+		}
+		parent, ok := items[item.Parent]
+		if !ok {
+			log.Printf("меню %s: родительский пункт %q для %q не найден", name, item.Parent, item.Name)
+			continue
+		}
+		parents[item] = parent
+	}
+
+	// Пункты, ссылающиеся друг на друга через parent, не попали бы в меню: цикл разрывается,
+	// пункт остаётся на верхнем уровне
+	for _, item := range all {
+		for p, steps := parents[item], 0; p != nil && steps <= len(all); p, steps = parents[p], steps+1 {
+			if p == item {
+				log.Printf("меню %s: цикл родительских пунктов у %q (parent: %q)", name, item.Name, item.Parent)
+				delete(parents, item)
+				break
+			}
+		}
+	}
+
+	var menu types.Menu
+
+	for _, item := range all {
+		if parent, ok := parents[item]; ok {
+			parent.Children = append(parent.Children, item)
+		} else {
+			menu = append(menu, item)
+		}
+	}
+
+	var sortMenu func(menu types.Menu)
+	sortMenu = func(menu types.Menu) {
+		sort.SliceStable(menu, func(i, j int) bool {
+			if menu[i].Weight != menu[j].Weight {
+				return menu[i].Weight < menu[j].Weight
+			}
+			return menu[i].Name < menu[j].Name
+		})
+		for _, item := range menu {
+			sortMenu(item.Children)
+		}
+	}
+	sortMenu(menu)
+
+	return menu
+}
+
+// activeMenu - копия меню с отметками Active и Ancestor для страницы current; второй результат -
+// в меню есть пункт текущей страницы
+func (core *App) activeMenu(menu types.Menu, current string) (types.Menu, bool) {
+	marked := make(types.Menu, 0, len(menu))
+	found := false
+
+	for _, item := range menu {
+		copied := *item
+		copied.Active = core.menuPath(item.URL) == current
+
+		var inChildren bool
+		copied.Children, inChildren = core.activeMenu(item.Children, current)
+
+		// Раздел: /posts/ отмечается для всех страниц внутри /posts/
+		section := core.menuPath(item.URL)
+		inSection := !copied.Active && section != "/" && strings.HasSuffix(section, "/") &&
+			strings.HasPrefix(current, section)
+
+		copied.Ancestor = inChildren || inSection
+		found = found || copied.Active || copied.Ancestor
+
+		marked = append(marked, &copied)
+	}
+
+	return marked, found
+}
+
+// menusFor - все меню с отметками текущей страницы для файла сборки fileName
+func (core *App) menusFor(fileName string) map[string]types.Menu {
+	current := core.menuPath(fileName)
+	menus := make(map[string]types.Menu, len(core.Menus))
+
+	for name, menu := range core.Menus {
+		menus[name], _ = core.activeMenu(menu, current)
+	}
+
+	return menus
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"bytes"
+	"github.com/globalmac/boyar/types"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+// menuString - меню в виде строки: имена через пробел, подпункты в скобках, ! - Active, ^ - Ancestor
+func menuString(menu types.Menu) string {
+	var parts []string
+	for _, item := range menu {
+		s := item.Name
+		if item.Active {
+			s += "!"
+		}
+		if item.Ancestor {
+			s += "^"
+		}
+		if item.HasChildren() {
+			s += "(" + menuString(item.Children) + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestMenuTree(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	core := &App{SiteConfig: SiteConfig{BaseURL: "https://example.ru"}}
+
+	tests := []struct {
+		name    string
+		entries []types.MenuEntry
+		want    string
+		warning string
+	}{
+		{
+			name: "вложенные пункты по weight",
+			entries: []types.MenuEntry{
+				{Name: "Статьи", URL: "/posts/", Weight: 2, Identifier: "posts"},
+				{Name: "Главная", URL: "/", Weight: 1},
+				{Name: "2024", URL: "/posts/2024/", Parent: "posts"},
+				{Name: "2023", URL: "/posts/2023/", Parent: "posts"},
+			},
+			want: "Главная Статьи(2023 2024)",
+		},
+		{
+			name:    "неизвестный родитель",
+			entries: []types.MenuEntry{{Name: "Сирота", Parent: "нет"}},
+			want:    "Сирота",
+			warning: "не найден",
+		},
+		{
+			name: "цикл родителей",
+			entries: []types.MenuEntry{
+				{Name: "А", Parent: "Б", Weight: 1},
+				{Name: "Б", Parent: "А", Weight: 2},
+				{Name: "В", Parent: "Б"},
+			},
+			want:    "А(Б(В))",
+			warning: "цикл",
+		},
+		{
+			name:    "пункт сам себе родитель",
+			entries: []types.MenuEntry{{Name: "А", Parent: "А"}},
+			want:    "А",
+			warning: "цикл",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+
+			if got := menuString(core.menuTree("main", tt.entries)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tt.warning != "" && !strings.Contains(logs.String(), tt.warning) {
+				t.Errorf("нет предупреждения %q: %s", tt.warning, logs.String())
+			}
+		})
+	}
+}
+
+func TestMenusFor(t *testing.T) {
+	core := &App{SiteConfig: SiteConfig{BaseURL: "https://example.ru"}}
+	core.Menus = map[string]types.Menu{"main": core.menuTree("main", []types.MenuEntry{
+		{Name: "Главная", URL: "/", Weight: 1},
+		{Name: "Статьи", URL: "/posts/", Weight: 2},
+		{Name: "2024", URL: "/posts/2024/", Parent: "Статьи"},
+		{Name: "GitHub", URL: "https://github.com", Weight: 3},
+	})}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "index.html", want: "Главная! Статьи(2024) GitHub"},
+		{file: "posts/index.html", want: "Главная Статьи!(2024) GitHub"},
+		{file: "posts/2024/index.html", want: "Главная Статьи^(2024!) GitHub"},
+		{file: "posts/2024/hello.html", want: "Главная Статьи^(2024^) GitHub"},
+		{file: "about.html", want: "Главная Статьи(2024) GitHub"},
+	}
+
+	for _, tt := range tests {
+		if got := menuString(core.menusFor(tt.file)["main"]); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.file, got, tt.want)
+		}
+	}
+
+	if menuString(core.Menus["main"]) != "Главная Статьи(2024) GitHub" {
+		t.Error("menusFor изменил общее меню")
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"path"
+	"strings"
+)
+
+// pageURL - адрес и файл сборки отдельной страницы: url из front matter или /<slug>.html.
+// URL без расширения собирается в <url>/index.html
+func pageURL(url, slug string) (string, string) {
+	if url == "" {
+		return "/" + slug + ".html", slug + ".html"
+	}
+
+	url = "/" + strings.TrimLeft(url, "/")
+
+	if path.Ext(url) != "" {
+		return url, strings.TrimPrefix(url, "/")
+	}
+
+	url = strings.TrimRight(url, "/") + "/"
+	if url == "/" {
+		return url, "index.html"
+	}
+
+	return url, strings.TrimPrefix(url, "/") + "index.html"
+}
+
+// pageTemplate - шаблон отдельной страницы: layout из front matter, затем page.html → detail.html
+func (core *App) pageTemplate(page types.Post) string {
+	if page.Layout != "" {
+		return core.postTemplate(page)
+	}
+
+	if name, ok := core.lookupTemplate("page.html", "detail.html"); ok {
+		return name
+	}
+
+	return "page.html"
+}
+
+// MakePages - отдельные страницы из Markdown-файлов в корне content_dir и HTML-шаблоны из списка pages.
+// Страницы не попадают в списки постов, категории и RSS, но есть в sitemap
+func (core *App) MakePages() {
+	files := map[string]bool{}
+
+	for _, page := range core.Pages {
+		_, fileName := pageURL(page.URL, page.Slug)
+		files[fileName] = true
+
+		data := map[string]interface{}{
+			"Post":       page,
+			"Page":       page,
+			"IsSingular": true,
+			"IsPage":     true,
+		}
+
+		err := core.SaveAsHTML(fileName, core.pageTemplate(page), data)
+		if err != nil {
+			core.AddError(err)
+		}
+	}
+
+	for _, page := range core.SiteConfig.Pages {
+		// Markdown-страница с тем же адресом заменяет HTML-шаблон
+		if files[page] {
+			continue
+		}
+
+		err := core.SaveAsHTML(page, page, map[string]interface{}{})
+		if err != nil {
+			core.AddError(err)
+		}
+	}
+}
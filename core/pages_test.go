@@ -0,0 +1,56 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"html/template"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPageURL(t *testing.T) {
+	tests := []struct {
+		url, slug string
+		wantURL   string
+		wantFile  string
+	}{
+		{"", "about", "/about.html", "about.html"},
+		{"/contacts.html", "about", "/contacts.html", "contacts.html"},
+		{"docs/guide", "about", "/docs/guide/", "docs/guide/index.html"},
+		{"/", "about", "/", "index.html"},
+	}
+
+	for _, tt := range tests {
+		url, file := pageURL(tt.url, tt.slug)
+		if url != tt.wantURL || file != tt.wantFile {
+			t.Errorf("pageURL(%q, %q) = %q, %q, want %q, %q", tt.url, tt.slug, url, file, tt.wantURL, tt.wantFile)
+		}
+	}
+}
+
+func TestMakePagesKeepsIndex(t *testing.T) {
+	core := &App{
+		OutputDir: t.TempDir(),
+		templates: map[string]*template.Template{
+			"index.html": template.Must(template.New("index.html").Parse("главная")),
+			"page.html":  template.Must(template.New("page.html").Parse("{{.Page.Title}}")),
+		},
+		Pages: types.Posts{{Slug: "home", Title: "страница", URL: "/"}},
+	}
+
+	if err := core.SaveAsHTML("index.html", "index.html", map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	core.MakePages()
+
+	got, err := os.ReadFile(filepath.Join(core.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "главная" {
+		t.Errorf("index.html = %q, главная перезаписана страницей", got)
+	}
+	if len(core.Errors) != 1 {
+		t.Errorf("Errors = %v, want одна ошибка о занятом адресе", core.Errors)
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"github.com/globalmac/boyar/types"
+	"strconv"
+	"strings"
+)
+
+// PaginationConfig - адреса страниц списков (секция pagination: в конфиге). path - адрес второй
+// и следующих страниц относительно папки списка: page/:num.html или page/:num/ (page/2/index.html);
+// window - сколько номеров страниц показывать по обе стороны от текущей
+type PaginationConfig struct {
+	Path   string `yaml:"path"`
+	Window int    `yaml:"window"`
+}
+
+// DefaultPaginationConfig - настройки пагинации по умолчанию
+func DefaultPaginationConfig() PaginationConfig {
+	return PaginationConfig{
+		Path:   "page/:num.html",
+		Window: 2,
+	}
+}
+
+// validate - в шаблоне адреса должен быть :num, иначе все страницы после первой попадут в один файл
+func (c PaginationConfig) validate() error {
+	if !strings.Contains(c.Path, ":num") {
+		return fmt.Errorf("pagination.path: в адресе %q нет :num (например, page/:num.html)", c.Path)
+	}
+
+	return nil
+}
+
+// paginationURL - адрес страницы num списка из папки base ("" - главная, "posts", "tags/go")
+func (core *App) paginationURL(base string, num int) string {
+	p := strings.ReplaceAll(core.SiteConfig.Pagination.Path, ":num", strconv.Itoa(num))
+
+	url := "/" + strings.TrimLeft(p, "/")
+	if base = strings.Trim(base, "/"); base != "" {
+		url = "/" + base + url
+	}
+
+	url, _ = pageURL(url, "")
+
+	return url
+}
+
+// paginate - страницы списка по perPage постов (0 - все на одной странице); first - адрес первой
+// страницы, base - папка списка для адресов следующих. Пустой список - одна страница без постов
+func (core *App) paginate(posts types.Posts, perPage int, base, first string) []types.Paginator {
+	total := 1
+	if perPage > 0 && len(posts) > perPage {
+		total = (len(posts) + perPage - 1) / perPage
+	} else {
+		perPage = len(posts)
+	}
+
+	urls := []string{first}
+	for num := 2; num <= total; num++ {
+		urls = append(urls, core.paginationURL(base, num))
+	}
+
+	pagers := make([]types.Paginator, total)
+
+	for i := range pagers {
+		start, end := i*perPage, (i+1)*perPage
+		if end > len(posts) {
+			end = len(posts)
+		}
+
+		pager := types.Paginator{
+			Posts:      posts[start:end],
+			PageNumber: i + 1,
+			TotalPages: total,
+			TotalItems: len(posts),
+			PerPage:    perPage,
+			URL:        urls[i],
+			First:      urls[0],
+			Last:       urls[total-1],
+			Pages:      pageLinks(urls, i+1, core.SiteConfig.Pagination.Window),
+		}
+		if i > 0 {
+			pager.Prev = urls[i-1]
+		}
+		if i < total-1 {
+			pager.Next = urls[i+1]
+		}
+
+		pagers[i] = pager
+	}
+
+	return pagers
+}
+
+// pageLinks - номера страниц для навигации: первая, последняя и window страниц вокруг текущей,
+// пропущенные номера заменяются одним разрывом; разрыв в одну страницу показывается номером
+func pageLinks(urls []string, current, window int) []types.PageLink {
+	var links []types.PageLink
+
+	total := len(urls)
+
+	for num := 1; num <= total; num++ {
+		distance := num - current
+		if distance < 0 {
+			distance = -distance
+		}
+
+		show := num == 1 || num == total || distance <= window ||
+			(distance == window+1 && (num == 2 || num == total-1))
+
+		if show {
+			links = append(links, types.PageLink{Number: num, URL: urls[num-1], Current: num == current})
+		} else if !links[len(links)-1].Gap {
+			links = append(links, types.PageLink{Gap: true})
+		}
+	}
+
+	return links
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPaginationURL(t *testing.T) {
+	tests := []struct {
+		path string
+		base string
+		num  int
+		want string
+	}{
+		{path: "page/:num.html", base: "", num: 2, want: "/page/2.html"},
+		{path: "page/:num.html", base: "posts", num: 3, want: "/posts/page/3.html"},
+		{path: "page/:num.html", base: "/tags/go/", num: 2, want: "/tags/go/page/2.html"},
+		{path: "page/:num/", base: "posts/2024", num: 2, want: "/posts/2024/page/2/"},
+		{path: "/p:num", base: "news", num: 10, want: "/news/p10/"},
+	}
+
+	for _, tt := range tests {
+		core := &App{SiteConfig: SiteConfig{Pagination: PaginationConfig{Path: tt.path}}}
+		if got := core.paginationURL(tt.base, tt.num); got != tt.want {
+			t.Errorf("%s %q %d: got %q, want %q", tt.path, tt.base, tt.num, got, tt.want)
+		}
+	}
+}
+
+// pageNumbers - номера страниц навигации: … - разрыв, * - текущая страница
+func pageNumbers(links []types.PageLink) []interface{} {
+	var nums []interface{}
+	for _, l := range links {
+		switch {
+		case l.Gap:
+			nums = append(nums, "…")
+		case l.Current:
+			nums = append(nums, "*")
+		default:
+			nums = append(nums, l.Number)
+		}
+	}
+	return nums
+}
+
+func TestPageLinks(t *testing.T) {
+	tests := []struct {
+		total, current, window int
+		want                   []interface{}
+	}{
+		{total: 1, current: 1, window: 2, want: []interface{}{"*"}},
+		{total: 5, current: 3, window: 2, want: []interface{}{1, 2, "*", 4, 5}},
+		{total: 10, current: 1, window: 2, want: []interface{}{"*", 2, 3, "…", 10}},
+		{total: 10, current: 5, window: 2, want: []interface{}{1, 2, 3, 4, "*", 6, 7, "…", 10}},
+		{total: 10, current: 6, window: 1, want: []interface{}{1, "…", 5, "*", 7, "…", 10}},
+		{total: 10, current: 10, window: 2, want: []interface{}{1, "…", 8, 9, "*"}},
+		{total: 7, current: 4, window: 0, want: []interface{}{1, "…", "*", "…", 7}},
+	}
+
+	for _, tt := range tests {
+		urls := make([]string, tt.total)
+		for i := range urls {
+			urls[i] = string(rune('a' + i))
+		}
+
+		links := pageLinks(urls, tt.current, tt.window)
+		if got := pageNumbers(links); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d/%d окно %d: got %v, want %v", tt.current, tt.total, tt.window, got, tt.want)
+		}
+		for _, l := range links {
+			if !l.Gap && l.URL != urls[l.Number-1] {
+				t.Errorf("страница %d: адрес %q", l.Number, l.URL)
+			}
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	core := &App{SiteConfig: SiteConfig{Pagination: DefaultPaginationConfig()}}
+	posts := make(types.Posts, 5)
+
+	pagers := core.paginate(posts, 2, "posts", "/posts/")
+	if len(pagers) != 3 {
+		t.Fatalf("страниц: %d", len(pagers))
+	}
+
+	last := pagers[2]
+	if len(last.Posts) != 1 || last.TotalItems != 5 || last.Prev != "/posts/page/2.html" || last.Next != "" ||
+		last.First != "/posts/" || last.Last != "/posts/page/3.html" {
+		t.Errorf("последняя страница: %+v", last)
+	}
+
+	if empty := core.paginate(nil, 10, "", "/"); len(empty) != 1 || empty[0].URL != "/" {
+		t.Errorf("пустой список: %+v", empty)
+	}
+}
+
+func TestLoadConfigRejectsPaginationWithoutNum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("pagination:\n    path: page.html\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("ожидалась ошибка для pagination.path без :num")
+	}
+}
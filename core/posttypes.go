@@ -0,0 +1,150 @@
+package core
+
+import (
+	"fmt"
+	"github.com/globalmac/boyar/types"
+	"gopkg.in/yaml.v3"
+	"path"
+	"strings"
+)
+
+// PostTypeConfig - настройки типа постов (секция post_types: в конфиге). Значение может быть
+// строкой - тогда это только название: post_types: {news: Новости}. Вложенные типы (news/2024)
+// наследуют настройки родителя, кроме названия
+type PostTypeConfig struct {
+	Label     string `yaml:"label"`
+	PerPage   int    `yaml:"per_page"`
+	Sort      string `yaml:"sort"`
+	Order     string `yaml:"order"`
+	Permalink string `yaml:"permalink"`
+	Layout    string `yaml:"layout"`
+	Home      *bool  `yaml:"home"`
+	RSS       *bool  `yaml:"rss"`
+	Sitemap   *bool  `yaml:"sitemap"`
+	Search    *bool  `yaml:"search"`
+	Tags      *bool  `yaml:"tags"`
+}
+
+// UnmarshalYAML - тип постов строкой (название) или картой настроек
+func (c *PostTypeConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		c.Label = value.Value
+		return nil
+	}
+
+	type plain PostTypeConfig
+	return value.Decode((*plain)(c))
+}
+
+// flag - значение флага; не заданный флаг включён
+func flag(b *bool) bool {
+	return b == nil || *b
+}
+
+// OnHome - посты типа выводятся на главной
+func (c PostTypeConfig) OnHome() bool { return flag(c.Home) }
+
+// InRSS - посты типа попадают в RSS
+func (c PostTypeConfig) InRSS() bool { return flag(c.RSS) }
+
+// InSitemap - посты типа попадают в sitemap
+func (c PostTypeConfig) InSitemap() bool { return flag(c.Sitemap) }
+
+// InSearch - посты типа попадают в search.json
+func (c PostTypeConfig) InSearch() bool { return flag(c.Search) }
+
+// HasTags - для постов типа собираются страницы тегов
+func (c PostTypeConfig) HasTags() bool { return flag(c.Tags) }
+
+// postTypeConfig - настройки типа постов с учётом родительских типов
+func (core *App) postTypeConfig(postType string) PostTypeConfig {
+	cfg := core.SiteConfig.PostTypesValues[postType]
+
+	for parent := path.Dir(postType); parent != "." && parent != "/"; parent = path.Dir(parent) {
+		p, ok := core.SiteConfig.PostTypesValues[parent]
+		if !ok {
+			continue
+		}
+
+		if cfg.PerPage == 0 {
+			cfg.PerPage = p.PerPage
+		}
+		if cfg.Sort == "" {
+			cfg.Sort = p.Sort
+		}
+		if cfg.Order == "" {
+			cfg.Order = p.Order
+		}
+		if cfg.Permalink == "" {
+			cfg.Permalink = p.Permalink
+		}
+		if cfg.Layout == "" {
+			cfg.Layout = p.Layout
+		}
+		for _, f := range []struct{ dst, src **bool }{
+			{&cfg.Home, &p.Home}, {&cfg.RSS, &p.RSS}, {&cfg.Sitemap, &p.Sitemap},
+			{&cfg.Search, &p.Search}, {&cfg.Tags, &p.Tags},
+		} {
+			if *f.dst == nil {
+				*f.dst = *f.src
+			}
+		}
+	}
+
+	return cfg
+}
+
+// filterPosts - посты, для типов которых allow разрешает вывод (главная, RSS, sitemap, поиск, теги);
+// скрытые посты (unlisted: true) не выводятся нигде
+func (core *App) filterPosts(posts types.Posts, allow func(PostTypeConfig) bool) types.Posts {
+	var filtered types.Posts
+
+	for _, post := range posts {
+		if !post.Unlisted && allow(core.postTypeConfig(post.Type)) {
+			filtered = append(filtered, post)
+		}
+	}
+
+	return filtered
+}
+
+// listedPosts - посты без unlisted: true; скрытые посты собираются, но не попадают в списки
+func listedPosts(posts types.Posts) types.Posts {
+	var listed types.Posts
+
+	for _, post := range posts {
+		if !post.Unlisted {
+			listed = append(listed, post)
+		}
+	}
+
+	return listed
+}
+
+// indexedPosts - посты для sitemap: без unlisted: true и noindex: true
+func indexedPosts(posts types.Posts) types.Posts {
+	var indexed types.Posts
+
+	for _, post := range listedPosts(posts) {
+		if !post.NoIndex {
+			indexed = append(indexed, post)
+		}
+	}
+
+	return indexed
+}
+
+// expandPermalink - адрес поста по шаблону permalink: :type, :section (первая папка типа),
+// :slug, :year, :month, :day. Например: /:type/:year/:slug/
+func expandPermalink(pattern string, post types.Post) string {
+	section, _, _ := strings.Cut(post.Type, "/")
+
+	return strings.NewReplacer(
+		":type", post.Type,
+		":section", section,
+		":slug", post.Slug,
+		":year", fmt.Sprintf("%04d", post.Date.Year()),
+		":month", fmt.Sprintf("%02d", int(post.Date.Month())),
+		":day", fmt.Sprintf("%02d", post.Date.Day()),
+	).Replace(pattern)
+}
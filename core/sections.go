@@ -0,0 +1,52 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"github.com/yuin/goldmark/parser"
+	"strings"
+)
+
+// sectionFile - файл с описанием раздела (категории) в папке контента
+const sectionFile = "_index.md"
+
+// makeSection - раздел из _index.md: заголовок, описание, вводный текст, обложка, сортировка и размер страницы
+func (core *App) makeSection(postType, path string, fmd types.MarkdownPost, body string) types.Section {
+	section := types.Section{
+		Type:        postType,
+		Title:       fmd.Title,
+		Description: fmd.Description,
+		Keywords:    fmd.Keywords,
+		Cover:       fmd.Cover,
+		Image:       fmd.Image,
+		Sort:        fmd.Sort,
+		Order:       fmd.Order,
+		PerPage:     fmd.PerPage,
+	}
+
+	if strings.TrimSpace(body) != "" {
+		pc := parser.NewContext()
+		pc.Set(markdownSourceKey, path)
+
+		content, err := core.markdownRender(body, postType, pc)
+		if err != nil {
+			core.contentError(path, "", err)
+		}
+		section.Content = content
+	}
+
+	return section
+}
+
+// section - раздел по типу постов; если _index.md нет или в нём нет заголовка, заголовок берётся из post_types
+func (core *App) section(postType string) types.Section {
+	section, ok := core.Sections[postType]
+	if !ok {
+		section.Type = postType
+	}
+
+	if section.Title == "" {
+		section.Title = core.SiteConfig.PostTypesValues[postType].Label
+	}
+
+	return section
+}
@@ -0,0 +1,344 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/globalmac/boyar/types"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Shortcode - данные, передаваемые в шаблон шорткода
+type Shortcode struct {
+	Name   string
+	Params map[string]string
+	Args   []string
+	Inner  template.HTML
+	Post   types.Post
+	Site   SiteConfig
+}
+
+// Get - получение аргумента шорткода по номеру (позиционный) или по имени (именованный)
+func (sc Shortcode) Get(key interface{}) string {
+	switch k := key.(type) {
+	case int:
+		if k >= 0 && k < len(sc.Args) {
+			return sc.Args[k]
+		}
+	case string:
+		return sc.Params[k]
+	}
+
+	return ""
+}
+
+// shortcodeTag - разобранный открывающий/закрывающий тег шорткода
+type shortcodeTag struct {
+	start, end int
+	markdown   bool
+	closing    bool
+	selfClose  bool
+	name       string
+	args       string
+}
+
+// shortcodeTagPattern - регулярное выражение тега шорткода с парными разделителями open и close
+func shortcodeTagPattern(open, close string) *regexp.Regexp {
+	return regexp.MustCompile(`\{\{(` + open + `)\s*(/?)\s*([A-Za-z0-9_\-]+)((?:"[^"]*"|` + "`[^`]*`" + `|[^"` + "`" + `])*?)\s*(/?)\s*` + close + `\}\}`)
+}
+
+// shortcodeTagRes - теги {{< >}} и {{% %}}; разделители в одном теге не смешиваются
+var shortcodeTagRes = []*regexp.Regexp{shortcodeTagPattern("<", ">"), shortcodeTagPattern("%", "%")}
+
+var shortcodeEscapedRe = regexp.MustCompile(`\{\{([<%])/\*(.*?)\*/([>%])\}\}`)
+
+// shortcodePlaceholder - метка, которая подменяется готовым HTML после рендера Markdown
+const shortcodePlaceholder = "BOYARSC%dCSRAYOB"
+
+// shortcodeRenderer - обработка шорткодов одного поста
+type shortcodeRenderer struct {
+	app          *App
+	post         types.Post
+	placeholders []string
+	escaped      []string
+}
+
+// loadShortcodes - загрузка встроенных шорткодов и шаблонов shortcodes/*.html из source_dir и тем
+func (core *App) loadShortcodes() error {
+	t, err := template.New("").Funcs(core.templateFuncs()).Parse(builtinShortcodes)
+	if err != nil {
+		return err
+	}
+	core.Shortcodes = t
+
+	files, err := core.lookupFiles("shortcodes", "*.html")
+	if err != nil || len(files) == 0 {
+		return err
+	}
+
+	_, err = t.ParseFiles(files...)
+
+	return err
+}
+
+// renderShortcodes - подстановка шорткодов в Markdown до рендера. Возвращает Markdown
+// с метками и функцию, заменяющую метки на HTML в уже отрендеренном контенте
+func (core *App) renderShortcodes(markdown string, post types.Post) (string, func(string) string, error) {
+	r := &shortcodeRenderer{app: core, post: post}
+
+	markdown = shortcodeEscapedRe.ReplaceAllStringFunc(markdown, func(s string) string {
+		m := shortcodeEscapedRe.FindStringSubmatch(s)
+		r.escaped = append(r.escaped, "{{"+m[1]+m[2]+m[3]+"}}")
+		return r.placeholder(len(r.escaped)-1, "E")
+	})
+
+	result, err := r.expand(markdown)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return result, r.restore, nil
+}
+
+// placeholder - формирование метки для подстановки
+func (r *shortcodeRenderer) placeholder(i int, kind string) string {
+	return kind + fmt.Sprintf(shortcodePlaceholder, i)
+}
+
+// restore - замена меток на результат работы шорткодов
+func (r *shortcodeRenderer) restore(content string) string {
+	for i, html := range r.placeholders {
+		p := r.placeholder(i, "H")
+		content = strings.Replace(content, "<p>"+p+"</p>", html, 1)
+		content = strings.Replace(content, p, html, 1)
+	}
+
+	for i, s := range r.escaped {
+		content = strings.Replace(content, r.placeholder(i, "E"), template.HTMLEscapeString(s), -1)
+	}
+
+	return content
+}
+
+// expand - рекурсивная обработка шорткодов в тексте
+func (r *shortcodeRenderer) expand(s string) (string, error) {
+	var out strings.Builder
+
+	for {
+		tag, ok := nextShortcodeTag(s, 0)
+		if !ok {
+			out.WriteString(s)
+			break
+		}
+
+		if tag.closing {
+			return "", fmt.Errorf("закрывающий шорткод {{%s /%s}} без открывающего", tagDelim(tag), tag.name)
+		}
+
+		out.WriteString(s[:tag.start])
+
+		var inner string
+		var hasInner bool
+		rest := s[tag.end:]
+
+		if !tag.selfClose {
+			if closeStart, closeEnd, found := findClosingShortcode(rest, tag.name); found {
+				inner, hasInner = rest[:closeStart], true
+				rest = rest[closeEnd:]
+			}
+		}
+
+		html, err := r.render(tag, inner, hasInner)
+		if err != nil {
+			return "", err
+		}
+
+		if tag.markdown {
+			out.WriteString(html)
+		} else {
+			r.placeholders = append(r.placeholders, html)
+			out.WriteString(r.placeholder(len(r.placeholders)-1, "H"))
+		}
+
+		s = rest
+	}
+
+	return out.String(), nil
+}
+
+// render - выполнение шаблона шорткода
+func (r *shortcodeRenderer) render(tag shortcodeTag, inner string, hasInner bool) (string, error) {
+	var tpl *template.Template
+	if r.app.Shortcodes != nil {
+		tpl = r.app.Shortcodes.Lookup(tag.name + ".html")
+	}
+	if tpl == nil {
+		return "", fmt.Errorf("неизвестный шорткод %q", tag.name)
+	}
+
+	params, args, err := parseShortcodeArgs(tag.args)
+	if err != nil {
+		return "", fmt.Errorf("шорткод %q: %w", tag.name, err)
+	}
+
+	if hasInner {
+		inner, err = r.expand(inner)
+		if err != nil {
+			return "", err
+		}
+		if !tag.markdown {
+			inner = r.restore(inner)
+		}
+	}
+
+	data := Shortcode{
+		Name:   tag.name,
+		Params: params,
+		Args:   args,
+		Inner:  template.HTML(strings.TrimSpace(inner)),
+		Post:   r.post,
+		Site:   r.app.SiteConfig,
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("шорткод %q: %w", tag.name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// nextShortcodeTag - поиск следующего тега шорткода начиная с позиции from
+func nextShortcodeTag(s string, from int) (shortcodeTag, bool) {
+	var loc []int
+	for _, re := range shortcodeTagRes {
+		if l := re.FindStringSubmatchIndex(s[from:]); l != nil && (loc == nil || l[0] < loc[0]) {
+			loc = l
+		}
+	}
+	if loc == nil {
+		return shortcodeTag{}, false
+	}
+
+	sub := func(n int) string {
+		if loc[2*n] < 0 {
+			return ""
+		}
+		return s[from+loc[2*n] : from+loc[2*n+1]]
+	}
+
+	return shortcodeTag{
+		start:     from + loc[0],
+		end:       from + loc[1],
+		markdown:  sub(1) == "%",
+		closing:   sub(2) == "/",
+		name:      sub(3),
+		args:      strings.TrimSpace(sub(4)),
+		selfClose: sub(5) == "/",
+	}, true
+}
+
+// findClosingShortcode - поиск парного закрывающего тега с учётом вложенности
+func findClosingShortcode(s, name string) (int, int, bool) {
+	depth := 0
+	pos := 0
+
+	for {
+		tag, ok := nextShortcodeTag(s, pos)
+		if !ok {
+			return 0, 0, false
+		}
+		pos = tag.end
+
+		if tag.name != name {
+			continue
+		}
+
+		if tag.closing {
+			if depth == 0 {
+				return tag.start, tag.end, true
+			}
+			depth--
+		} else if !tag.selfClose {
+			depth++
+		}
+	}
+}
+
+// parseShortcodeArgs - разбор аргументов шорткода: позиционных и вида key="value"
+func parseShortcodeArgs(s string) (map[string]string, []string, error) {
+	params := map[string]string{}
+	var args []string
+
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		var key string
+
+		if i := strings.IndexAny(s, "= \t\n\"`"); i > 0 && s[i] == '=' {
+			key, s = s[:i], s[i+1:]
+		}
+
+		value, rest, err := readShortcodeValue(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		s = rest
+
+		if key != "" {
+			params[key] = value
+		} else {
+			args = append(args, value)
+		}
+	}
+
+	return params, args, nil
+}
+
+// readShortcodeValue - чтение одного значения аргумента (в кавычках или до пробела)
+func readShortcodeValue(s string) (string, string, error) {
+	if s == "" {
+		return "", "", nil
+	}
+
+	switch s[0] {
+	case '"':
+		end := 1
+		for end < len(s) && (s[end] != '"' || s[end-1] == '\\') {
+			end++
+		}
+		if end >= len(s) {
+			return "", "", fmt.Errorf("незакрытая кавычка в аргументах: %s", s)
+		}
+		value, err := strconv.Unquote(s[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+		return value, s[end+1:], nil
+	case '`':
+		end := strings.IndexByte(s[1:], '`')
+		if end < 0 {
+			return "", "", fmt.Errorf("незакрытая кавычка в аргументах: %s", s)
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+
+	if i := strings.IndexAny(s, " \t\n"); i >= 0 {
+		return s[:i], s[i:], nil
+	}
+
+	return s, "", nil
+}
+
+// tagDelim - символ-разделитель тега шорткода
+func tagDelim(tag shortcodeTag) string {
+	if tag.markdown {
+		return "%"
+	}
+	return "<"
+}
+
+// hasShortcodes - быстрая проверка наличия шорткодов в тексте
+func hasShortcodes(s string) bool {
+	return strings.Contains(s, "{{<") || strings.Contains(s, "{{%")
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"bytes"
+	"github.com/globalmac/boyar/types"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/renderer/html"
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseShortcodeArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		params map[string]string
+		args   []string
+		err    bool
+	}{
+		{name: "пусто", in: "", params: map[string]string{}},
+		{name: "позиционные", in: `abc "два слова" ` + "`raw \"q\"`", params: map[string]string{}, args: []string{"abc", "два слова", `raw "q"`}},
+		{name: "именованные", in: `id=42 title="Привет, мир" note=` + "`a b`", params: map[string]string{"id": "42", "title": "Привет, мир", "note": "a b"}},
+		{name: "вперемешку", in: `first key="v" last`, params: map[string]string{"key": "v"}, args: []string{"first", "last"}},
+		{name: "экранированная кавычка", in: `"a \"b\" c"`, params: map[string]string{}, args: []string{`a "b" c`}},
+		{name: "незакрытая кавычка", in: `title="abc`, err: true},
+		{name: "незакрытый обратный апостроф", in: "`abc", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, args, err := parseShortcodeArgs(tt.in)
+			if tt.err {
+				if err == nil {
+					t.Fatal("ожидалась ошибка")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(params, tt.params) || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("\n got: %v %q\nwant: %v %q", params, args, tt.params, tt.args)
+			}
+		})
+	}
+}
+
+func TestNextShortcodeTagDelimiters(t *testing.T) {
+	tests := []struct {
+		in    string
+		found bool
+		name  string
+	}{
+		{in: "{{< youtube abc >}}", found: true, name: "youtube"},
+		{in: "{{% note %}}", found: true, name: "note"},
+		{in: "{{< youtube abc %}}", found: false},
+		{in: "{{% note >}}", found: false},
+		{in: "{{< a %}} {{% b %}}", found: true, name: "b"},
+	}
+
+	for _, tt := range tests {
+		tag, ok := nextShortcodeTag(tt.in, 0)
+		if ok != tt.found || tag.name != tt.name {
+			t.Errorf("%s: got %v %q, want %v %q", tt.in, ok, tag.name, tt.found, tt.name)
+		}
+	}
+}
+
+func TestRenderShortcodesRoundTrip(t *testing.T) {
+	shortcodes := template.Must(template.New("").Parse(
+		`{{define "hi.html"}}<b>{{.Get 0}}</b>{{end}}` +
+			`{{define "box.html"}}<div class="box">{{.Inner}}</div>{{end}}` +
+			`{{define "note.html"}}> {{.Inner}}{{end}}`,
+	))
+	core := &App{Shortcodes: shortcodes}
+	md := goldmark.New(goldmark.WithRendererOptions(html.WithUnsafe()))
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "HTML-шорткод в строке",
+			in:   `Привет, {{< hi "мир" >}}!`,
+			want: "<p>Привет, <b>мир</b>!</p>",
+		},
+		{
+			name: "HTML-шорткод отдельным абзацем",
+			in:   "{{< hi x >}}",
+			want: "<b>x</b>",
+		},
+		{
+			name: "вложенные шорткоды",
+			in:   `{{< box >}}{{< hi "в" >}}{{< /box >}}`,
+			want: `<div class="box"><b>в</b></div>`,
+		},
+		{
+			name: "Markdown-шорткод рендерится вместе с текстом",
+			in:   "{{% note %}}**жирный**{{% /note %}}",
+			want: "<blockquote>\n<p><strong>жирный</strong></p>\n</blockquote>",
+		},
+		{
+			name: "экранированный шорткод",
+			in:   "Пример: {{</* hi x */>}}",
+			want: "<p>Пример: {{&lt; hi x &gt;}}</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, restore, err := core.renderShortcodes(tt.in, types.Post{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := md.Convert([]byte(expanded), &buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.TrimSpace(restore(buf.String())); got != tt.want {
+				t.Errorf("\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderShortcodesErrors(t *testing.T) {
+	core := &App{Shortcodes: template.Must(template.New("").Parse(`{{define "hi.html"}}hi{{end}}`))}
+
+	for _, in := range []string{"{{< nope >}}", "{{< /hi >}}"} {
+		if _, _, err := core.renderShortcodes(in, types.Post{}); err == nil {
+			t.Errorf("%s: ожидалась ошибка", in)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"bytes"
+	"github.com/globalmac/boyar/types"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ListSort - сортировка списка постов (секция lists: в конфиге - home и tags)
+type ListSort struct {
+	Sort  string `yaml:"sort"`
+	Order string `yaml:"order"`
+}
+
+// titleCollator - сравнение заголовков по правилам русского алфавита; Collator не потокобезопасен
+var (
+	titleCollator   = collate.New(language.Russian, collate.IgnoreCase)
+	titleCollatorMu sync.Mutex
+)
+
+// titleKeys - ключи сортировки заголовков, сравниваемые побайтово
+func titleKeys(posts types.Posts) [][]byte {
+	titleCollatorMu.Lock()
+	defer titleCollatorMu.Unlock()
+
+	var buf collate.Buffer
+	keys := make([][]byte, len(posts))
+	for i, post := range posts {
+		keys[i] = append([]byte(nil), titleCollator.KeyFromString(&buf, post.Title)...)
+		buf.Reset()
+	}
+
+	return keys
+}
+
+// sortPosts - копия списка постов, отсортированная по полю by в порядке order (asc, desc):
+// date и lastmod - по умолчанию сначала новые, title - по алфавиту с учётом русского языка,
+// weight - по возрастанию веса, посты без веса в конце при любом порядке. При равенстве сравниваются даты в том же порядке
+func sortPosts(posts types.Posts, by, order string) types.Posts {
+	sorted := append(types.Posts(nil), posts...)
+
+	by = strings.ToLower(by)
+	order = strings.ToLower(order)
+	if order == "" {
+		order = "desc"
+		if by == "title" || by == "weight" {
+			order = "asc"
+		}
+	}
+
+	var keys [][]byte
+	if by == "title" {
+		keys = titleKeys(sorted)
+	}
+
+	// compare - порядок по возрастанию: отрицательное значение, если i раньше j
+	compare := func(i, j int) int {
+		a, b := sorted[i], sorted[j]
+
+		switch by {
+		case "title":
+			return bytes.Compare(keys[i], keys[j])
+		case "lastmod":
+			return a.Lastmod.Compare(b.Lastmod)
+		case "weight":
+			return a.Weight - b.Weight
+		default:
+			return a.Date.Compare(b.Date)
+		}
+	}
+
+	idx := make([]int, len(sorted))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	sort.SliceStable(idx, func(i, j int) bool {
+		// Посты без веса в конце списка при любом порядке
+		if by == "weight" {
+			if noA, noB := sorted[idx[i]].Weight == 0, sorted[idx[j]].Weight == 0; noA != noB {
+				return noB
+			}
+		}
+
+		c := compare(idx[i], idx[j])
+		if c == 0 {
+			c = sorted[idx[i]].Date.Compare(sorted[idx[j]].Date)
+		}
+		if order == "desc" {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	result := make(types.Posts, len(sorted))
+	for i, n := range idx {
+		result[i] = sorted[n]
+	}
+
+	return result
+}
+
+// pinFirst - закреплённые посты (pinned: true) в начале списка с сохранением порядка,
+// чтобы они были вверху первой страницы и не повторялись на следующих
+func pinFirst(posts types.Posts) types.Posts {
+	sorted := append(types.Posts(nil), posts...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Pinned && !sorted[j].Pinned
+	})
+
+	return sorted
+}
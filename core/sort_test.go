@@ -0,0 +1,62 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortPosts(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	posts := types.Posts{
+		{Slug: "a", Title: "ёжик", Date: day(1), Weight: 2},
+		{Slug: "b", Title: "Арбуз", Date: day(2), Lastmod: day(9)},
+		{Slug: "c", Title: "Ежевика", Date: day(3), Weight: 1},
+		{Slug: "d", Title: "арбуз", Date: day(4), Weight: 2},
+		{Slug: "e", Title: "Яблоко", Date: day(5), Lastmod: day(6), Pinned: true},
+	}
+
+	tests := []struct {
+		by, order string
+		want      []string
+	}{
+		{by: "", order: "", want: []string{"e", "d", "c", "b", "a"}},
+		{by: "date", order: "asc", want: []string{"a", "b", "c", "d", "e"}},
+		{by: "lastmod", order: "", want: []string{"b", "e", "d", "c", "a"}},
+		// равные заголовки и веса - по дате в том же направлении
+		{by: "title", order: "", want: []string{"b", "d", "c", "a", "e"}},
+		{by: "title", order: "desc", want: []string{"e", "a", "c", "d", "b"}},
+		{by: "weight", order: "", want: []string{"c", "a", "d", "b", "e"}},
+		// посты без веса в конце в обоих направлениях
+		{by: "weight", order: "desc", want: []string{"d", "a", "c", "e", "b"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, post := range sortPosts(posts, tt.by, tt.order) {
+			got = append(got, post.Slug)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: got %v, want %v", tt.by, tt.order, got, tt.want)
+		}
+	}
+
+	if posts[0].Slug != "a" {
+		t.Error("sortPosts изменил исходный список")
+	}
+}
+
+func TestPinFirst(t *testing.T) {
+	posts := types.Posts{{Slug: "a"}, {Slug: "b", Pinned: true}, {Slug: "c"}, {Slug: "d", Pinned: true}}
+
+	var got []string
+	for _, post := range pinFirst(posts) {
+		got = append(got, post.Slug)
+	}
+
+	if want := []string{"b", "d", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SummaryConfig - автоматический анонс, если в посте нет <!--more--> (секция summary: в конфиге)
+type SummaryConfig struct {
+	Words             int    `yaml:"words"`
+	Chars             int    `yaml:"chars"`
+	Sentence          bool   `yaml:"sentence"`
+	Ellipsis          string `yaml:"ellipsis"`
+	DescriptionLength int    `yaml:"description_length"`
+}
+
+// DefaultSummaryConfig - настройки анонса по умолчанию
+func DefaultSummaryConfig() SummaryConfig {
+	return SummaryConfig{
+		Words:             70,
+		Sentence:          true,
+		Ellipsis:          "…",
+		DescriptionLength: 160,
+	}
+}
+
+// voidElements - теги без закрывающей пары
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlCut - возможная точка обрезки HTML и открытые к этому моменту теги
+type htmlCut struct {
+	pos   int
+	count int
+	open  []string
+}
+
+// truncateHTML - обрезка HTML по количеству слов или символов текста (по границе предложения,
+// если возможно) с закрытием незакрытых тегов. Второе значение - был ли текст обрезан
+func truncateHTML(s string, words, chars int, sentence bool, ellipsis string) (string, bool) {
+	if words <= 0 && chars <= 0 {
+		return s, false
+	}
+
+	var open []string
+	var wordEnd, sentenceEnd *htmlCut
+	count := 0
+	inWord := false
+	limit := words
+	if limit <= 0 {
+		limit = chars
+	}
+
+	snapshot := func(pos int) *htmlCut {
+		return &htmlCut{pos: pos, count: count, open: append([]string(nil), open...)}
+	}
+
+	cutAt := -1
+
+scan:
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '<':
+			end := strings.IndexByte(s[i:], '>')
+			if end < 0 {
+				i = len(s)
+				continue
+			}
+			tag := s[i : i+end+1]
+
+			if inWord {
+				wordEnd = snapshot(i)
+				inWord = false
+			}
+
+			name, closing, selfClosing := parseHTMLTag(tag)
+			switch {
+			case name == "" || selfClosing || voidElements[name]:
+			case closing:
+				for j := len(open) - 1; j >= 0; j-- {
+					if open[j] == name {
+						open = open[:j]
+						break
+					}
+				}
+			default:
+				open = append(open, name)
+			}
+
+			i += end + 1
+			continue
+		case '&':
+			if end := strings.IndexByte(s[i:], ';'); end > 0 && end < 10 {
+				if words <= 0 {
+					count++
+					if count > chars {
+						cutAt = i
+						break scan
+					}
+				} else if !inWord {
+					inWord = true
+					count++
+					if count > words {
+						cutAt = i
+						break scan
+					}
+				}
+				i += end + 1
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+
+		if unicode.IsSpace(r) {
+			if inWord {
+				wordEnd = snapshot(i)
+				inWord = false
+			}
+			i += size
+			continue
+		}
+
+		if words > 0 {
+			if !inWord {
+				inWord = true
+				count++
+				if count > words {
+					cutAt = i
+					break scan
+				}
+			}
+		} else {
+			inWord = true
+			count++
+			if count > chars {
+				cutAt = i
+				break scan
+			}
+		}
+
+		i += size
+
+		if strings.ContainsRune(".!?…", r) {
+			next, _ := utf8.DecodeRuneInString(s[i:])
+			if i >= len(s) || unicode.IsSpace(next) || next == '<' {
+				sentenceEnd = snapshot(i)
+			}
+		}
+	}
+
+	if cutAt < 0 {
+		return s, false
+	}
+
+	cut := wordEnd
+	suffix := ellipsis
+	if sentence && sentenceEnd != nil && sentenceEnd.count*2 >= limit {
+		cut, suffix = sentenceEnd, ""
+	}
+	if cut == nil {
+		cut = &htmlCut{pos: cutAt, open: open}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRightFunc(s[:cut.pos], func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == ';' || r == ':' || r == '—' || r == '-'
+	}))
+	b.WriteString(suffix)
+	for j := len(cut.open) - 1; j >= 0; j-- {
+		b.WriteString("</" + cut.open[j] + ">")
+	}
+
+	return b.String(), true
+}
+
+// parseHTMLTag - имя тега и его тип (закрывающий, самозакрывающийся); для комментариев пустое имя
+func parseHTMLTag(tag string) (string, bool, bool) {
+	if strings.HasPrefix(tag, "<!") || strings.HasPrefix(tag, "<?") {
+		return "", false, false
+	}
+
+	inner := strings.TrimSuffix(strings.TrimPrefix(tag, "<"), ">")
+	closing := strings.HasPrefix(inner, "/")
+	selfClosing := strings.HasSuffix(inner, "/")
+	inner = strings.Trim(inner, "/ \t\n")
+
+	end := strings.IndexFunc(inner, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '/'
+	})
+	if end >= 0 {
+		inner = inner[:end]
+	}
+
+	return strings.ToLower(inner), closing, selfClosing
+}
+
+// truncateText - обрезка простого текста до n символов по границе слова
+func truncateText(s string, n int, ellipsis string) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+	cut := n
+	for cut > 0 && !isBreakingSpace(runes[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		cut = n
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + ellipsis
+}
+
+// makeSummary - анонс и описание поста по цепочке: summary из front matter → <!--more--> → автоанонс;
+// описание: description → summary из front matter → текст анонса
+func (core *App) makeSummary(post *types.Post, fmd types.MarkdownPost, renderSummary func(string) (string, error)) error {
+	cfg := core.SiteConfig.Summary
+
+	summary, reminder, found := splitContent(post.Content)
+	post.Reminder = reminder
+	post.Truncated = found
+
+	if !found {
+		summary, post.Truncated = truncateHTML(post.Content, cfg.Words, cfg.Chars, cfg.Sentence, cfg.Ellipsis)
+	}
+
+	if strings.TrimSpace(fmd.Summary) != "" {
+		rendered, err := renderSummary(fmd.Summary)
+		if err != nil {
+			return err
+		}
+		summary = strings.TrimSpace(rendered)
+		post.Truncated = true
+	}
+
+	post.Summary = summary
+	post.SummaryClean = removeHTMLTags(summary)
+
+	if post.Description == "" {
+		post.Description = truncateText(post.SummaryClean, cfg.DescriptionLength, cfg.Ellipsis)
+	}
+
+	return nil
+}
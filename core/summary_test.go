@@ -0,0 +1,206 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"testing"
+)
+
+func TestTruncateHTML(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		words     int
+		chars     int
+		sentence  bool
+		want      string
+		truncated bool
+	}{
+		{
+			name:      "незакрытый строчный тег",
+			in:        "<p>Один <strong>два три</strong> четыре</p>",
+			words:     2,
+			want:      "<p>Один <strong>два…</strong></p>",
+			truncated: true,
+		},
+		{
+			name:      "вложенные теги",
+			in:        `<p>Один <em>два <a href="x">три четыре</a> пять</em></p>`,
+			words:     3,
+			want:      `<p>Один <em>два <a href="x">три…</a></em></p>`,
+			truncated: true,
+		},
+		{
+			name:      "сущность считается словом",
+			in:        "<p>Tom &amp; Jerry &amp; Co</p>",
+			words:     3,
+			want:      "<p>Tom &amp; Jerry…</p>",
+			truncated: true,
+		},
+		{
+			name:      "кириллица по символам",
+			in:        "<p>Привет мир</p>",
+			chars:     8,
+			want:      "<p>Привет…</p>",
+			truncated: true,
+		},
+		{
+			name:      "кириллица внутри слова",
+			in:        "<p>Приветствую</p>",
+			chars:     3,
+			want:      "<p>При…</p>",
+			truncated: true,
+		},
+		{
+			name:      "граница предложения",
+			in:        "<p>Первое предложение тут. Второе предложение длинное очень</p>",
+			words:     5,
+			sentence:  true,
+			want:      "<p>Первое предложение тут.</p>",
+			truncated: true,
+		},
+		{
+			name:      "без границы предложения",
+			in:        "<p>Первое предложение тут. Второе предложение длинное очень</p>",
+			words:     5,
+			want:      "<p>Первое предложение тут. Второе предложение…</p>",
+			truncated: true,
+		},
+		{
+			name:      "слишком короткое предложение",
+			in:        "<p>Коротко. Дальше много разных слов подряд здесь</p>",
+			words:     6,
+			sentence:  true,
+			want:      "<p>Коротко. Дальше много разных слов подряд…</p>",
+			truncated: true,
+		},
+		{
+			name:  "текст короче лимита",
+			in:    "<p>Один два</p>",
+			words: 5,
+			want:  "<p>Один два</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := truncateHTML(tt.in, tt.words, tt.chars, tt.sentence, "…")
+			if got != tt.want || truncated != tt.truncated {
+				t.Errorf("\n got: %q %v\nwant: %q %v", got, truncated, tt.want, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestRemoveHTMLTags(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"<p>Один\n  <b>два</b></p>\n<p>три</p>", "Один два три"},
+		{"<p>10\u00a0км и\u00a0&nbsp;5</p>", "10\u00a0км и\u00a0\u00a05"},
+		{"<p>Запустите <code>go build</code>:</p><pre><code>go build ./...</code></pre><p>Готово</p>", "Запустите : Готово"},
+		{"<div class=\"highlight\"><div class=\"highlight-title\">main.go</div><pre>package main</pre></div>Текст", "Текст"},
+		{"<div class='lite-embed lite-embed-vk'><div><button>Смотреть</button></div></div>После", "После"},
+		{"<div><div>Вложенный</div> текст</div>", "Вложенный текст"},
+		{"a &lt; b &amp;&amp; c", "a < b && c"},
+	}
+
+	for _, tt := range tests {
+		if got := removeHTMLTags(tt.in); got != tt.want {
+			t.Errorf("removeHTMLTags(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateTextKeepsNbsp(t *testing.T) {
+	if got, want := truncateText("Дистанция 10\u00a0км", 13, "…"), "Дистанция…"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// codeAndEmbed - пост с блоком кода с заголовком и встраиванием видео
+const codeAndEmbed = "<p>Пример:</p>\n<div class=\"highlight language-go\">\n<div class=\"highlight-title\">main.go</div>\n" +
+	"<pre class=\"chroma\"><code>package main</code></pre>\n</div>\n" +
+	"<div class=\"lite-embed lite-embed-youtube\" data-src=\"https://www.youtube-nocookie.com/embed/x\">\n" +
+	"<button type=\"button\" class=\"lite-embed-play\" aria-label=\"Видео\"><span>Видео</span></button>\n" +
+	"<noscript><a href=\"https://youtu.be/x\">Видео</a></noscript>\n</div>\n<p>Конец</p>"
+
+func TestMakeSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		fmd         types.MarkdownPost
+		words       int
+		chars       int
+		summary     string
+		description string
+	}{
+		{
+			name:        "summary из front matter важнее <!--more-->",
+			content:     "<p>До</p><!--more--><p>После</p>",
+			fmd:         types.MarkdownPost{Summary: "Анонс"},
+			words:       70,
+			summary:     "<p>Анонс</p>",
+			description: "Анонс",
+		},
+		{
+			name:        "<!--more-->",
+			content:     "<p>До</p><!--more--><p>После</p>",
+			words:       70,
+			summary:     "<p>До</p>",
+			description: "До",
+		},
+		{
+			name:        "автоанонс по словам",
+			content:     "<p>Один два три четыре</p>",
+			words:       2,
+			summary:     "<p>Один два…</p>",
+			description: "Один два…",
+		},
+		{
+			name:        "автоанонс по символам",
+			content:     "<p>Один два три четыре</p>",
+			chars:       6,
+			summary:     "<p>Один…</p>",
+			description: "Один…",
+		},
+		{
+			name:        "описание без кода и встраиваний",
+			content:     codeAndEmbed,
+			words:       70,
+			summary:     codeAndEmbed,
+			description: "Пример: Конец",
+		},
+		{
+			name:        "description из front matter",
+			content:     "<p>Один два</p>",
+			fmd:         types.MarkdownPost{Description: "Своё описание"},
+			words:       70,
+			summary:     "<p>Один два</p>",
+			description: "Своё описание",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := &App{SiteConfig: SiteConfig{Summary: SummaryConfig{
+				Words:             tt.words,
+				Chars:             tt.chars,
+				Ellipsis:          "…",
+				DescriptionLength: 160,
+			}}}
+
+			post := types.Post{Content: tt.content, Description: tt.fmd.Description}
+			err := core.makeSummary(&post, tt.fmd, func(s string) (string, error) {
+				return "<p>" + s + "</p>\n", nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if post.Summary != tt.summary || post.Description != tt.description {
+				t.Errorf("\n got: %q %q\nwant: %q %q", post.Summary, post.Description, tt.summary, tt.description)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/globalmac/boyar/types"
+	"html/template"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// parsedTemplates - разобранные шаблоны страниц (layouts + файл страницы) и отпечаток файлов,
+// по которому определяется, менялись ли шаблоны с прошлой сборки
+type parsedTemplates struct {
+	fingerprint string
+	pages       map[string]*template.Template
+	errs        map[string]error
+}
+
+// templateCache - шаблоны, разобранные при предыдущих сборках (в режиме serve), по набору директорий исходников
+var templateCache = struct {
+	sync.Mutex
+	dirs map[string]*parsedTemplates
+}{dirs: map[string]*parsedTemplates{}}
+
+// templateFile - шаблон страницы: имя относительно source_dir или темы (news/detail.html) и путь к файлу
+type templateFile struct {
+	Name string
+	Path string
+}
+
+// templateDirs - служебные директории source_dir, в которых нет шаблонов страниц
+var templateDirs = map[string]bool{"layouts": true, "shortcodes": true, "static": true}
+
+// templateFiles - файлы layouts/*.html и шаблоны страниц *.html (включая поддиректории типов постов)
+// из source_dir и тем
+func (core *App) templateFiles() ([]string, []templateFile, error) {
+	layouts, err := core.lookupFiles("layouts", "*.html")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := map[string]string{}
+	for _, dir := range core.sourceDirs() {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == dir && errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+
+			if d.IsDir() {
+				if templateDirs[rel] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if strings.HasSuffix(rel, ".html") {
+				if _, ok := found[rel]; !ok {
+					found[rel] = path
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pages := make([]templateFile, 0, len(found))
+	for name, path := range found {
+		pages = append(pages, templateFile{Name: name, Path: path})
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Name < pages[j].Name
+	})
+
+	return layouts, pages, nil
+}
+
+// templatesFingerprint - отпечаток набора файлов по имени, размеру и времени изменения
+func templatesFingerprint(files []string) string {
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+
+	var b strings.Builder
+	for _, file := range sorted {
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintf(&b, "%s:-;", file)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return b.String()
+}
+
+// parseTemplates - разбор layouts и каждого шаблона страницы. Ошибки собираются по файлам,
+// шаблон с ошибкой не попадает в набор, остальные продолжают работать
+func (core *App) parseTemplates(layouts []string, pages []templateFile) *parsedTemplates {
+	parsed := &parsedTemplates{
+		pages: map[string]*template.Template{},
+		errs:  map[string]error{},
+	}
+
+	base := template.New("").Funcs(core.templateFuncs())
+	if len(layouts) > 0 {
+		if _, err := base.ParseFiles(layouts...); err != nil {
+			for _, page := range pages {
+				parsed.errs[page.Name] = err
+			}
+			return parsed
+		}
+	}
+
+	for _, page := range pages {
+		data, err := os.ReadFile(page.Path)
+		if err != nil {
+			parsed.errs[page.Name] = err
+			continue
+		}
+
+		// Имя шаблона - путь относительно source_dir, чтобы ошибки указывали на нужный файл
+		t, err := base.Clone()
+		if err == nil {
+			_, err = t.New(page.Name).Parse(string(data))
+		}
+		if err != nil {
+			parsed.errs[page.Name] = err
+			continue
+		}
+
+		parsed.pages[page.Name] = t
+	}
+
+	return parsed
+}
+
+// LoadTemplates - загрузка и проверка шаблонов один раз на сборку. Если файлы шаблонов не менялись
+// с прошлой сборки (serve), используются уже разобранные шаблоны
+func (core *App) LoadTemplates() error {
+	layouts, pages, err := core.templateFiles()
+	if err != nil {
+		return err
+	}
+
+	files := append([]string(nil), layouts...)
+	for _, page := range pages {
+		files = append(files, page.Path)
+	}
+	fingerprint := templatesFingerprint(files)
+	key := strings.Join(core.sourceDirs(), string(filepath.ListSeparator))
+
+	templateCache.Lock()
+	parsed, ok := templateCache.dirs[key]
+	if !ok || parsed.fingerprint != fingerprint {
+		parsed = core.parseTemplates(layouts, pages)
+		parsed.fingerprint = fingerprint
+		templateCache.dirs[key] = parsed
+	}
+
+	// Разобранные шаблоны не исполняются напрямую: каждая сборка получает копию
+	// с функциями, привязанными к своему App
+	core.templates = make(map[string]*template.Template, len(parsed.pages))
+	errs := map[string]error{}
+	for name, t := range parsed.pages {
+		clone, err := t.Clone()
+		if err != nil {
+			errs[name] = err
+			continue
+		}
+		core.templates[name] = clone.Funcs(core.templateFuncs())
+	}
+	for name, err := range parsed.errs {
+		errs[name] = err
+	}
+	templateCache.Unlock()
+
+	core.templateErrs = errs
+
+	result := []error{core.checkThemes()}
+	for _, page := range pages {
+		if err, ok := errs[page.Name]; ok {
+			result = append(result, err)
+		}
+	}
+
+	return errors.Join(result...)
+}
+
+// template - шаблон страницы из кэша сборки
+func (core *App) template(name string) (*template.Template, error) {
+	if core.templates == nil {
+		core.AddError(core.LoadTemplates())
+	}
+
+	t, ok := core.templates[name]
+	if !ok {
+		if err, ok := core.templateErrs[name]; ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("шаблон %s не найден в %s", name, strings.Join(core.sourceDirs(), ", "))
+	}
+
+	return t, nil
+}
+
+// lookupTemplate - первый существующий шаблон из списка
+func (core *App) lookupTemplate(names ...string) (string, bool) {
+	if core.templates == nil {
+		core.AddError(core.LoadTemplates())
+	}
+
+	for _, name := range names {
+		if _, ok := core.templates[name]; ok {
+			return name, true
+		}
+		// Шаблон с ошибкой тоже выбирается, чтобы ошибка попала в отчёт, а не сменился шаблон
+		if _, ok := core.templateErrs[name]; ok {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// typeTemplates - шаблон name в директории типа поста и её родителях: news/world/x.html → news/x.html → x.html
+func typeTemplates(postType, name string) []string {
+	var names []string
+
+	for dir := strings.Trim(postType, "/"); dir != "" && dir != "."; dir = path.Dir(dir) {
+		names = append(names, dir+"/"+name)
+	}
+
+	return append(names, name)
+}
+
+// postTemplate - шаблон страницы поста: layout из front matter, затем <тип>/detail.html → detail.html
+func (core *App) postTemplate(post types.Post) string {
+	if post.Layout != "" {
+		layout := strings.TrimSuffix(post.Layout, ".html") + ".html"
+		if name, ok := core.lookupTemplate(typeTemplates(post.Type, layout)...); ok {
+			return name
+		}
+		// Страница собирается через detail.html, поэтому это предупреждение, а не ошибка сборки:
+		// в режиме serve ошибки закрывают весь сайт страницей с ошибками
+		log.Printf("%s/%s: шаблон layout %q не найден, используется detail.html", post.Type, post.Slug, post.Layout)
+	}
+
+	if name, ok := core.lookupTemplate(typeTemplates(post.Type, "detail.html")...); ok {
+		return name
+	}
+
+	return "detail.html"
+}
+
+// listTemplate - шаблон списка постов категории: <тип>/list.html → posts.html
+func (core *App) listTemplate(postType string) string {
+	names := typeTemplates(postType, "list.html")
+
+	if name, ok := core.lookupTemplate(append(names[:len(names)-1], "posts.html")...); ok {
+		return name
+	}
+
+	return "posts.html"
+}
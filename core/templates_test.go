@@ -0,0 +1,36 @@
+package core
+
+import (
+	"github.com/globalmac/boyar/types"
+	"html/template"
+	"testing"
+)
+
+func TestPostTemplate(t *testing.T) {
+	core := &App{templates: map[string]*template.Template{
+		"detail.html":       nil,
+		"news/detail.html":  nil,
+		"news/gallery.html": nil,
+	}}
+
+	tests := []struct {
+		post types.Post
+		want string
+	}{
+		{types.Post{Type: "posts"}, "detail.html"},
+		{types.Post{Type: "news/world"}, "news/detail.html"},
+		{types.Post{Type: "news/world", Layout: "gallery"}, "news/gallery.html"},
+		{types.Post{Type: "posts", Slug: "hello", Layout: "missing"}, "detail.html"},
+	}
+
+	for _, tt := range tests {
+		if got := core.postTemplate(tt.post); got != tt.want {
+			t.Errorf("postTemplate(%s, %q) = %q, want %q", tt.post.Type, tt.post.Layout, got, tt.want)
+		}
+	}
+
+	// Ненайденный layout - предупреждение: в serve ошибки сборки закрывают весь сайт
+	if len(core.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", core.Errors)
+	}
+}
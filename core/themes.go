@@ -0,0 +1,111 @@
+package core
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Themes - темы оформления сайта: theme: name или theme: [name, base]. Тема, указанная раньше,
+// переопределяет файлы следующих, а файлы source_dir переопределяют файлы всех тем
+type Themes []string
+
+// UnmarshalYAML - тема строкой или списком тем
+func (t *Themes) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		if value.Value != "" {
+			*t = Themes{value.Value}
+		}
+		return nil
+	}
+
+	var themes []string
+	if err := value.Decode(&themes); err != nil {
+		return err
+	}
+	*t = themes
+
+	return nil
+}
+
+// sourceDirs - директории с шаблонами, шорткодами и статикой по убыванию приоритета:
+// source_dir, затем themes_dir/<тема> в порядке из конфига
+func (core *App) sourceDirs() []string {
+	dirs := []string{filepath.Clean(core.SiteConfig.SourceDir)}
+
+	for _, theme := range core.SiteConfig.Theme {
+		dirs = append(dirs, filepath.Join(core.SiteConfig.ThemesDir, theme))
+	}
+
+	return dirs
+}
+
+// staticDirs - директории статики по убыванию приоритета
+func (core *App) staticDirs() []string {
+	var dirs []string
+
+	for _, dir := range core.sourceDirs() {
+		dirs = append(dirs, filepath.Join(dir, "static"))
+	}
+
+	return dirs
+}
+
+// checkThemes - все темы из конфига существуют
+func (core *App) checkThemes() error {
+	for _, theme := range core.SiteConfig.Theme {
+		dir := filepath.Join(core.SiteConfig.ThemesDir, theme)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("тема %q не найдена: нет директории %s", theme, dir)
+		}
+	}
+
+	return nil
+}
+
+// lookupFiles - файлы по маске в поддиректории source_dir и тем. Из одноимённых файлов
+// берётся файл директории с большим приоритетом; результат отсортирован по имени
+func (core *App) lookupFiles(subdir, pattern string) ([]string, error) {
+	found := map[string]string{}
+
+	for _, dir := range core.sourceDirs() {
+		files, err := filepath.Glob(filepath.Join(dir, subdir, pattern))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			name := filepath.Base(file)
+			if _, ok := found[name]; !ok {
+				found[name] = file
+			}
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]string, len(names))
+	for i, name := range names {
+		files[i] = found[name]
+	}
+
+	return files, nil
+}
+
+// lookupFile - путь к файлу в source_dir или темах с учётом приоритета
+func (core *App) lookupFile(subdir, name string) (string, bool) {
+	for _, dir := range core.sourceDirs() {
+		path := filepath.Join(dir, subdir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,343 @@
+package core
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"strings"
+	"unicode"
+)
+
+// TypographTransformer - русская типографика: «ёлочки» и „лапки“, тире, неразрывные пробелы, многоточия.
+// Код, ссылки-URL и сырой HTML не изменяются
+type TypographTransformer struct{}
+
+const (
+	nbsp        = "\u00a0"
+	objectRune  = '\uFFFC'
+	typoNewline = '\n'
+)
+
+// typographShortWords - короткие слова, после которых ставится неразрывный пробел
+var typographShortWords = map[string]bool{
+	"а": true, "и": true, "в": true, "к": true, "о": true, "с": true, "у": true, "я": true,
+	"во": true, "до": true, "за": true, "из": true, "ко": true, "на": true, "не": true, "ни": true,
+	"но": true, "об": true, "от": true, "по": true, "со": true, "то": true, "да": true,
+}
+
+// typographParticles - частицы, перед которыми ставится неразрывный пробел
+var typographParticles = map[string]bool{
+	"ли": true, "ль": true, "же": true, "ж": true, "бы": true, "б": true,
+}
+
+// typographUnits - единицы измерения и сокращения, которые не отрываются от числа
+var typographUnits = map[string]bool{
+	"мм": true, "см": true, "м": true, "км": true, "г": true, "кг": true, "т": true, "мг": true,
+	"л": true, "мл": true, "га": true, "с": true, "сек": true, "мин": true, "ч": true,
+	"руб": true, "р": true, "коп": true, "₽": true, "$": true, "€": true, "%": true, "°": true, "°C": true,
+	"тыс": true, "млн": true, "млрд": true, "шт": true, "гг": true, "в": true, "вв": true,
+	"кб": true, "мб": true, "гб": true, "тб": true, "Кб": true, "Мб": true, "Гб": true, "Тб": true,
+}
+
+// typographText - текст одного блока с привязкой рун к узлам AST
+type typographText struct {
+	runes     []rune
+	protected []bool
+	out       []string
+	chunks    []typographChunk
+}
+
+type typographChunk struct {
+	node       *ast.Text
+	start, end int
+}
+
+// Transform - обработка всех блоков с инлайн-содержимым
+func (t *TypographTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+
+		first := n.FirstChild()
+		if first == nil || first.Type() != ast.TypeInline {
+			return ast.WalkContinue, nil
+		}
+
+		tt := &typographText{}
+		tt.collect(n, source)
+		tt.process()
+		tt.apply(source)
+
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// collect - сбор текста блока; код и автоссылки заменяются защищённым символом
+func (tt *typographText) collect(parent ast.Node, source []byte) {
+	for c := parent.FirstChild(); c != nil; c = c.NextSibling() {
+		switch v := c.(type) {
+		case *ast.Text:
+			start := len(tt.runes)
+			value := []rune(string(v.Segment.Value(source)))
+			tt.push(value, v.IsRaw())
+			tt.chunks = append(tt.chunks, typographChunk{node: v, start: start, end: len(tt.runes)})
+			if v.SoftLineBreak() || v.HardLineBreak() {
+				tt.push([]rune{typoNewline}, true)
+			}
+		case *ast.String:
+			tt.push([]rune(string(v.Value)), true)
+		case *ast.RawHTML:
+			// теги прозрачны для правил, их содержимое не изменяется
+		case *ast.CodeSpan, *ast.AutoLink:
+			tt.push([]rune{objectRune}, true)
+		default:
+			tt.collect(c, source)
+		}
+	}
+}
+
+func (tt *typographText) push(runes []rune, protected bool) {
+	for _, r := range runes {
+		tt.runes = append(tt.runes, r)
+		tt.protected = append(tt.protected, protected)
+		tt.out = append(tt.out, string(r))
+	}
+}
+
+// apply - замена изменённых текстовых узлов на строки с новым содержимым
+func (tt *typographText) apply(source []byte) {
+	for _, chunk := range tt.chunks {
+		value := strings.Join(tt.out[chunk.start:chunk.end], "")
+		if value == string(chunk.node.Segment.Value(source)) {
+			continue
+		}
+
+		s := ast.NewString([]byte(value))
+		s.SetRaw(chunk.node.IsRaw())
+		chunk.node.Parent().InsertBefore(chunk.node.Parent(), chunk.node, s)
+		chunk.node.Segment = chunk.node.Segment.WithStop(chunk.node.Segment.Start)
+	}
+}
+
+// process - применение правил типографики
+func (tt *typographText) process() {
+	tt.protectURLs()
+	tt.ellipsis()
+	tt.dashes()
+	tt.quotes()
+	tt.spaces()
+}
+
+func (tt *typographText) at(i int) rune {
+	if i < 0 || i >= len(tt.runes) {
+		return ' '
+	}
+	return tt.runes[i]
+}
+
+func (tt *typographText) set(i int, s string) {
+	if i >= 0 && i < len(tt.runes) && !tt.protected[i] {
+		tt.out[i] = s
+	}
+}
+
+func (tt *typographText) editable(i int) bool {
+	return i >= 0 && i < len(tt.runes) && !tt.protected[i]
+}
+
+func isTypoSpace(r rune) bool {
+	return unicode.IsSpace(r)
+}
+
+func isTypoWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == objectRune
+}
+
+// protectURLs - URL, пути и e-mail в тексте не обрабатываются
+func (tt *typographText) protectURLs() {
+	start := 0
+	for i := 0; i <= len(tt.runes); i++ {
+		if i < len(tt.runes) && !unicode.IsSpace(tt.runes[i]) {
+			continue
+		}
+
+		word := string(tt.runes[start:i])
+		if strings.Contains(word, "://") || strings.HasPrefix(word, "www.") || strings.Contains(word, "@") {
+			for j := start; j < i; j++ {
+				tt.protected[j] = true
+			}
+		}
+		start = i + 1
+	}
+}
+
+// ellipsis - три точки в многоточие
+func (tt *typographText) ellipsis() {
+	for i := 0; i+2 < len(tt.runes); i++ {
+		if tt.runes[i] == '.' && tt.runes[i+1] == '.' && tt.runes[i+2] == '.' &&
+			tt.editable(i) && tt.editable(i+1) && tt.editable(i+2) && tt.at(i-1) != '.' && tt.at(i+3) != '.' {
+			tt.set(i, "…")
+			tt.set(i+1, "")
+			tt.set(i+2, "")
+			i += 2
+		}
+	}
+}
+
+// dashes - дефисы и двойные дефисы в тире, диапазоны чисел в короткое тире
+func (tt *typographText) dashes() {
+	for i := 0; i < len(tt.runes); i++ {
+		r := tt.runes[i]
+		if (r != '-' && r != '—') || !tt.editable(i) {
+			continue
+		}
+
+		// «--» и «---» в длинное тире
+		end := i
+		for end+1 < len(tt.runes) && tt.runes[end+1] == '-' && tt.editable(end+1) {
+			end++
+		}
+		if r == '-' && end > i && end-i <= 2 {
+			tt.set(i, "—")
+			for j := i + 1; j <= end; j++ {
+				tt.set(j, "")
+			}
+			r = '—'
+		} else if end > i {
+			i = end
+			continue
+		}
+
+		prev, next := tt.at(i-1), tt.at(end+1)
+
+		switch {
+		case r == '-' && isTypoSpace(prev) && isTypoSpace(next):
+			tt.set(i, "—")
+			r = '—'
+		case r == '-' && unicode.IsDigit(prev) && unicode.IsDigit(next) && tt.isNumberRange(i):
+			tt.set(i, "–")
+		}
+
+		if r == '—' && isTypoSpace(prev) && tt.editable(i-1) && prev != typoNewline {
+			tt.set(i-1, nbsp)
+		}
+
+		i = end
+	}
+}
+
+// isNumberRange - проверка, что дефис стоит между двумя числами, а не внутри даты или номера
+func (tt *typographText) isNumberRange(i int) bool {
+	left := i - 1
+	for left >= 0 && unicode.IsDigit(tt.runes[left]) {
+		left--
+	}
+	right := i + 1
+	for right < len(tt.runes) && unicode.IsDigit(tt.runes[right]) {
+		right++
+	}
+
+	return i-left-1 <= 4 && right-i-1 <= 4 && tt.at(left) != '-' && tt.at(right) != '-' &&
+		tt.at(left) != '+' && !unicode.IsLetter(tt.at(left)) && !unicode.IsLetter(tt.at(right))
+}
+
+// quotes - прямые кавычки в «ёлочки», вложенные в „лапки“
+func (tt *typographText) quotes() {
+	depth := 0
+
+	for i, r := range tt.runes {
+		if r != '"' || !tt.editable(i) || tt.at(i-1) == '\\' {
+			continue
+		}
+
+		prev, next := tt.at(i-1), tt.at(i+1)
+		opening := isTypoSpace(prev) || strings.ContainsRune("([{«„—–-", prev)
+		if !opening && !isTypoWord(prev) && !strings.ContainsRune(".,!?…:;)]»“\"'", prev) {
+			opening = isTypoWord(next)
+		}
+
+		if opening {
+			if depth == 0 {
+				tt.set(i, "«")
+			} else {
+				tt.set(i, "„")
+			}
+			depth++
+		} else {
+			if depth <= 1 {
+				tt.set(i, "»")
+			} else {
+				tt.set(i, "“")
+			}
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+}
+
+// spaces - неразрывные пробелы после предлогов, перед частицами, между числом и единицей измерения
+func (tt *typographText) spaces() {
+	words := tt.words()
+
+	for n, w := range words {
+		word := strings.ToLower(string(tt.runes[w[0]:w[1]]))
+		space := w[1]
+
+		if !isTypoSpace(tt.at(space)) || tt.at(space) == typoNewline || space+1 >= len(tt.runes) || unicode.IsSpace(tt.at(space+1)) {
+			continue
+		}
+
+		var nextWord string
+		if n+1 < len(words) && words[n+1][0] == space+1 {
+			nextWord = string(tt.runes[words[n+1][0]:words[n+1][1]])
+		}
+
+		switch {
+		case typographShortWords[word]:
+			tt.set(space, nbsp)
+		case typographParticles[strings.ToLower(nextWord)]:
+			tt.set(space, nbsp)
+		case isNumber(word) && typographUnits[strings.TrimRight(nextWord, ".")]:
+			tt.set(space, nbsp)
+		case word == "№" || word == "§":
+			tt.set(space, nbsp)
+		}
+	}
+}
+
+// words - границы слов (включая знаки № § % ₽ и т.п.) в тексте
+func (tt *typographText) words() [][2]int {
+	var words [][2]int
+
+	start := -1
+	for i := 0; i <= len(tt.runes); i++ {
+		inWord := i < len(tt.runes) && !unicode.IsSpace(tt.runes[i]) &&
+			(isTypoWord(tt.runes[i]) || strings.ContainsRune("№§%₽$€°", tt.runes[i]) ||
+				(start >= 0 && strings.ContainsRune(".,", tt.runes[i]) && unicode.IsDigit(tt.at(i+1))))
+
+		if inWord && start < 0 {
+			start = i
+		} else if !inWord && start >= 0 {
+			words = append(words, [2]int{start, i})
+			start = -1
+		}
+	}
+
+	return words
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) && r != '.' && r != ',' {
+			return false
+		}
+	}
+	return true
+}
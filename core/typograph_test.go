@@ -0,0 +1,93 @@
+package core
+
+import (
+	"bytes"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/util"
+	"strings"
+	"testing"
+)
+
+// typographHTML - рендер Markdown только с русской типографикой; неразрывный пробел заменён на ~
+func typographHTML(t *testing.T, markdown string) string {
+	t.Helper()
+
+	md := goldmark.New(goldmark.WithParserOptions(
+		parser.WithASTTransformers(util.Prioritized(&TypographTransformer{}, 9000)),
+	))
+
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(markdown), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.ReplaceAll(strings.TrimSpace(buf.String()), nbsp, "~")
+}
+
+func TestTypographTransformer(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "вложенные кавычки",
+			in:   `Он сказал: "Это "книга" для всех".`,
+			want: `<p>Он сказал: «Это „книга“ для всех».</p>`,
+		},
+		{
+			name: "код не изменяется",
+			in:   "Запусти `go \"test\" -- x` и \"смотри\"",
+			want: `<p>Запусти <code>go &quot;test&quot; -- x</code> и~«смотри»</p>`,
+		},
+		{
+			name: "ссылки и URL не изменяются",
+			in:   `Смотри [ссылку "тут"](http://example.com/a--b) и https://example.com/x--y "ок"`,
+			want: `<p>Смотри <a href="http://example.com/a--b">ссылку «тут»</a> и~https://example.com/x--y «ок»</p>`,
+		},
+		{
+			name: "диапазоны чисел и номера",
+			in:   "Годы 1941-1945, телефон 8-800-555-35-35, страницы 10-20",
+			want: `<p>Годы 1941–1945, телефон 8-800-555-35-35, страницы 10–20</p>`,
+		},
+		{
+			name: "текст из нескольких инлайн-узлов",
+			in:   `Мы - *лучшие* "*друзья*" и **"жирный"**`,
+			want: `<p>Мы~— <em>лучшие</em> «<em>друзья</em>» и~<strong>«жирный»</strong></p>`,
+		},
+		{
+			name: "тире после кода",
+			in:   "Это `код` - пример",
+			want: `<p>Это <code>код</code>~— пример</p>`,
+		},
+		{
+			name: "двойной дефис",
+			in:   "Слово -- другое",
+			want: `<p>Слово~— другое</p>`,
+		},
+		{
+			name: "многоточие",
+			in:   "Ждите...",
+			want: `<p>Ждите…</p>`,
+		},
+		{
+			name: "предлоги и единицы измерения",
+			in:   "В доме 5 кг и 10 руб.",
+			want: `<p>В~доме 5~кг и~10~руб.</p>`,
+		},
+		{
+			name: "блок кода не изменяется",
+			in:   "```\n\"a\" - b...\n```",
+			want: "<pre><code>&quot;a&quot; - b...\n</code></pre>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := typographHTML(t, tt.in); got != tt.want {
+				t.Errorf("\n got: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,20 +1,17 @@
 package core
 
 import (
-	"bytes"
 	"github.com/globalmac/boyar/types"
-	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
-	"github.com/yuin/goldmark/renderer/html"
 	"github.com/yuin/goldmark/text"
-	"github.com/yuin/goldmark/util"
 	"gopkg.in/yaml.v3"
-	"log"
+	"html"
 	"os"
+	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type ASTTransformer struct{}
@@ -35,6 +32,13 @@ func CreateDir(path string) error {
 func LoadConfig(path string) (SiteConfig, error) {
 
 	var config SiteConfig
+	config.Markdown = DefaultMarkdownConfig()
+	config.Links = DefaultLinkPolicy()
+	config.ImageProcessing = DefaultImageProcessingConfig()
+	config.Summary = DefaultSummaryConfig()
+	config.ThemesDir = "themes"
+	config.Pagination = DefaultPaginationConfig()
+
 	configFile, err := os.ReadFile(path)
 	if err != nil {
 		return config, err
@@ -45,6 +49,11 @@ func LoadConfig(path string) (SiteConfig, error) {
 		return config, err
 	}
 
+	err = config.Pagination.validate()
+	if err != nil {
+		return config, err
+	}
+
 	return config, err
 }
 
@@ -75,7 +84,7 @@ func slugifyWithExt(s string) string {
 }
 
 // markdownParser - парсинг страницы с разметкой
-func markdownParser(content string) (types.MarkdownPost, string) {
+func markdownParser(content string) (types.MarkdownPost, string, error) {
 
 	lines := strings.Split(content, "\n")
 	count := 1
@@ -108,9 +117,6 @@ func markdownParser(content string) (types.MarkdownPost, string) {
 	}
 
 	err := yaml.Unmarshal([]byte(parts[0]), &fmd)
-	if err != nil {
-		log.Println(err)
-	}
 
 	if fmd.Tags != nil {
 		for i, tag := range fmd.Tags {
@@ -118,7 +124,7 @@ func markdownParser(content string) (types.MarkdownPost, string) {
 		}
 	}
 
-	return fmd, body
+	return fmd, body, err
 }
 
 // sliceContains - поиск строки в слайсе
@@ -132,37 +138,15 @@ func sliceContains(s []string, str string) bool {
 	return false
 }
 
-// markdownRender - рендер Markdown
-func markdownRender(markdown string) (string, error) {
-	var buf bytes.Buffer
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.Table,
-			extension.Strikethrough,
-			extension.Linkify,
-			extension.Footnote,
-			extension.TaskList,
-		),
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(),
-			parser.WithASTTransformers(
-				util.Prioritized(&ASTTransformer{}, 10000),
-			),
-		),
-		goldmark.WithRendererOptions(
-			html.WithHardWraps(),
-			html.WithUnsafe(),
-		),
-	)
-	err := md.Convert([]byte(markdown), &buf)
-	if err != nil {
-		return "", err
+func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	policy, ok := pc.Get(linkPolicyKey).(LinkPolicy)
+	if !ok {
+		policy = DefaultLinkPolicy()
 	}
 
-	return buf.String(), nil
-}
+	source := reader.Source()
+	var autoLinks []*ast.AutoLink
 
-func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
 	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
@@ -170,36 +154,139 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 
 		switch v := n.(type) {
 		case *ast.Link:
-			link := v.Destination
-			if bytes.HasPrefix(link, []byte("http")) {
-				v.SetAttributeString("target", []byte("_blank"))
+			policy.apply(v, v.Destination)
+		case *ast.AutoLink:
+			if v.AutoLinkType == ast.AutoLinkURL {
+				autoLinks = append(autoLinks, v)
 			}
 		}
 
 		return ast.WalkContinue, nil
 	})
+
+	// Автоссылки не выводят дочерние узлы, поэтому для значка внешней ссылки превращаем их в обычные
+	for _, v := range autoLinks {
+		dest := v.URL(source)
+
+		if policy.Icon == "" || !policy.IsExternal(linkHost(dest)) {
+			policy.apply(v, dest)
+			continue
+		}
+
+		link := ast.NewLink()
+		link.Destination = dest
+		link.AppendChild(link, ast.NewString(v.Label(source)))
+		v.Parent().ReplaceChild(v.Parent(), v, link)
+		policy.apply(link, dest)
+	}
+}
+
+// plainSkipElements - элементы, текст которых не попадает в описание, RSS и search.json:
+// код, скрипты, кнопки и заглушки встраиваний
+var plainSkipElements = map[string]bool{
+	"pre": true, "code": true, "script": true, "style": true, "noscript": true, "template": true,
+	"button": true, "iframe": true, "svg": true,
 }
 
-// removeHTMLTags - очистка строки от HTML
-func removeHTMLTags(html string) string {
+// plainSkipClasses - классы обёрток блоков кода (с заголовком файла) и встраиваний
+var plainSkipClasses = map[string]bool{"highlight": true, "lite-embed": true}
+
+// classAttrRe - значение атрибута class в открывающем теге
+var classAttrRe = regexp.MustCompile(`\sclass\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// skipPlainElement - текст элемента пропускается при очистке от HTML
+func skipPlainElement(name, tag string) bool {
+	if plainSkipElements[name] {
+		return true
+	}
+
+	m := classAttrRe.FindStringSubmatch(tag)
+	if m == nil {
+		return false
+	}
+	for _, class := range strings.Fields(m[1] + m[2] + m[3]) {
+		if plainSkipClasses[class] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBreakingSpace - пробельный символ, по которому можно переносить и схлопывать текст;
+// неразрывные пробелы типографа к ним не относятся
+func isBreakingSpace(r rune) bool {
+	return unicode.IsSpace(r) && r != '\u00a0' && r != '\u202f'
+}
+
+// collapseSpaces - схлопывание пробелов и переводов строк в один пробел с сохранением неразрывных
+func collapseSpaces(s string) string {
+	var b strings.Builder
+	space := false
+
+	for _, r := range strings.TrimFunc(s, isBreakingSpace) {
+		if isBreakingSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// removeHTMLTags - очистка строки от HTML без текста кода и встраиваний (с расшифровкой сущностей
+// и схлопыванием пробелов)
+func removeHTMLTags(s string) string {
 	var result strings.Builder
-	var insideTag bool
+	var skip string
+	depth := 0
+
+	for i := 0; i < len(s); {
+		if s[i] == '<' {
+			end := strings.IndexByte(s[i:], '>')
+			if end < 0 {
+				break
+			}
+			tag := s[i : i+end+1]
+			i += end + 1
 
-	for _, char := range html {
-		if char == '<' {
-			insideTag = true
+			name, closing, selfClosing := parseHTMLTag(tag)
+			if name == "" || selfClosing || voidElements[name] {
+				continue
+			}
+
+			switch {
+			case skip == name && closing:
+				if depth--; depth == 0 {
+					skip = ""
+					result.WriteByte(' ')
+				}
+			case skip == name:
+				depth++
+			case skip == "" && !closing && skipPlainElement(name, tag):
+				skip, depth = name, 1
+			}
 			continue
 		}
-		if char == '>' {
-			insideTag = false
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+
+		if skip != "" {
 			continue
 		}
-		if !insideTag && !unicode.IsControl(char) {
-			result.WriteRune(char)
+		if unicode.IsControl(r) {
+			r = ' '
 		}
+		result.WriteRune(r)
 	}
 
-	return result.String()
+	return html.UnescapeString(collapseSpaces(result.String()))
 }
 
 func GetPwd() string {
@@ -3,15 +3,19 @@ module github.com/globalmac/boyar
 go 1.22
 
 require (
+	github.com/alecthomas/chroma/v2 v2.14.0
 	github.com/fsnotify/fsnotify v1.7.0
 	github.com/pkg/sftp v1.13.6
 	github.com/tdewolff/minify/v2 v2.20.19
 	github.com/yuin/goldmark v1.7.0
 	golang.org/x/crypto v0.21.0
+	golang.org/x/image v0.15.0
+	golang.org/x/text v0.14.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/dlclark/regexp2 v1.11.0 // indirect
 	github.com/kr/fs v0.1.0 // indirect
 	github.com/tdewolff/parse/v2 v2.7.12 // indirect
 	golang.org/x/sys v0.18.0 // indirect
@@ -0,0 +1,31 @@
+// Размытые заглушки изображений: фон из data-placeholder до загрузки картинки
+document.querySelectorAll('img[data-placeholder]').forEach(function (img) {
+    if (img.complete) {
+        return;
+    }
+    img.style.backgroundImage = 'url(' + img.dataset.placeholder + ')';
+    img.addEventListener('load', function () {
+        img.style.backgroundImage = '';
+    }, {once: true});
+});
+
+// Встраивания с загрузкой по клику: превью из data-thumbnail, iframe из data-src
+document.querySelectorAll('.lite-embed[data-src]').forEach(function (embed) {
+    var button = embed.querySelector('.lite-embed-play');
+    if (!button) {
+        return;
+    }
+    if (embed.dataset.thumbnail) {
+        embed.style.backgroundImage = 'url("' + embed.dataset.thumbnail + '")';
+    }
+    button.addEventListener('click', function () {
+        var frame = document.createElement('iframe');
+        frame.src = embed.dataset.src;
+        frame.title = button.getAttribute('aria-label');
+        frame.allow = 'autoplay; encrypted-media; fullscreen; picture-in-picture';
+        frame.allowFullscreen = true;
+        embed.classList.add('lite-embed-loaded');
+        embed.style.backgroundImage = '';
+        embed.replaceChildren(frame);
+    }, {once: true});
+});
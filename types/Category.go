@@ -0,0 +1,41 @@
+package types
+
+import "strings"
+
+type Category struct {
+	Type        string
+	Slug        string
+	Title       string
+	Description string
+	Parent      *Category
+	Children    []*Category
+	Depth       int
+	Count       int
+	Total       int
+}
+
+type Breadcrumb struct {
+	Title string
+	URL   string
+}
+
+// Permarlink - адрес страницы категории
+func (c *Category) Permarlink() string {
+	return "/" + c.Type + "/"
+}
+
+// Contains - пост или категория postType находится в этой категории или её подкатегориях
+func (c *Category) Contains(postType string) bool {
+	return postType == c.Type || strings.HasPrefix(postType, c.Type+"/")
+}
+
+// Ancestors - родительские категории от корня
+func (c *Category) Ancestors() []*Category {
+	var ancestors []*Category
+
+	for p := c.Parent; p != nil; p = p.Parent {
+		ancestors = append([]*Category{p}, ancestors...)
+	}
+
+	return ancestors
+}
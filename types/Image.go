@@ -0,0 +1,52 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ImageVariant struct {
+	Name   string
+	URL    string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+type ImageSet struct {
+	Src         string
+	Width       int
+	Height      int
+	Variants    []ImageVariant
+	Placeholder string
+}
+
+// Srcset - значение атрибута srcset из вариантов изображения (кроме обрезанных)
+func (set ImageSet) Srcset() string {
+	var parts []string
+
+	for _, v := range set.Variants {
+		if v.Crop {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %dw", v.URL, v.Width))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Variant - вариант изображения по имени пресета; если его нет - исходное изображение
+func (set ImageSet) Variant(name string) ImageVariant {
+	for _, v := range set.Variants {
+		if v.Name == name {
+			return v
+		}
+	}
+
+	return ImageVariant{Name: name, URL: set.Src, Width: set.Width, Height: set.Height}
+}
+
+// IsProcessed - изображение прошло обработку и имеет варианты
+func (set ImageSet) IsProcessed() bool {
+	return len(set.Variants) > 0
+}
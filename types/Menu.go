@@ -0,0 +1,79 @@
+package types
+
+import "gopkg.in/yaml.v3"
+
+// MenuEntry - пункт меню из конфига или front matter; parent - identifier (или name) родительского пункта
+type MenuEntry struct {
+	Name       string `yaml:"name"`
+	URL        string `yaml:"url"`
+	Weight     int    `yaml:"weight"`
+	Parent     string `yaml:"parent"`
+	Identifier string `yaml:"identifier"`
+	Icon       string `yaml:"icon"`
+}
+
+// Key - идентификатор пункта для ссылок из parent: identifier, а если его нет - name
+func (e MenuEntry) Key() string {
+	if e.Identifier != "" {
+		return e.Identifier
+	}
+	return e.Name
+}
+
+// MenuItem - пункт меню с подпунктами. Active - пункт текущей страницы,
+// Ancestor - текущая страница среди подпунктов или внутри раздела пункта
+type MenuItem struct {
+	MenuEntry
+	Children Menu
+	Active   bool
+	Ancestor bool
+}
+
+// HasChildren - у пункта есть подпункты
+func (m *MenuItem) HasChildren() bool {
+	return len(m.Children) > 0
+}
+
+// IsCurrent - пункт текущей страницы или её раздела
+func (m *MenuItem) IsCurrent() bool {
+	return m.Active || m.Ancestor
+}
+
+// Menu - пункты меню одного уровня, отсортированные по weight и name
+type Menu []*MenuItem
+
+// PageMenus - меню, в которые входит страница: menu: main, menu: [main, footer]
+// или menu: {main: {name: "О нас", weight: 10}}
+type PageMenus map[string]MenuEntry
+
+// UnmarshalYAML - меню строкой, списком или картой с параметрами пункта
+func (m *PageMenus) UnmarshalYAML(value *yaml.Node) error {
+	menus := PageMenus{}
+
+	switch value.Kind {
+	case yaml.ScalarNode:
+		if value.Value != "" {
+			menus[value.Value] = MenuEntry{}
+		}
+	case yaml.SequenceNode:
+		var names []string
+		if err := value.Decode(&names); err != nil {
+			return err
+		}
+		for _, name := range names {
+			menus[name] = MenuEntry{}
+		}
+	default:
+		var entries map[string]MenuEntry
+		if err := value.Decode(&entries); err != nil {
+			return err
+		}
+		for name, entry := range entries {
+			menus[name] = entry
+		}
+	}
+
+	*m = menus
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package types
+
+// Paginator - страница списка постов (главная, категория, тег) со ссылками на соседние страницы
+type Paginator struct {
+	Posts      Posts
+	PageNumber int
+	TotalPages int
+	TotalItems int
+	PerPage    int
+	URL        string
+	First      string
+	Last       string
+	Prev       string
+	Next       string
+	Pages      []PageLink
+}
+
+// PageLink - номер страницы в навигации; Gap - пропуск «…» между номерами
+type PageLink struct {
+	Number  int
+	URL     string
+	Current bool
+	Gap     bool
+}
+
+// HasPrev - есть предыдущая страница
+func (p Paginator) HasPrev() bool {
+	return p.Prev != ""
+}
+
+// HasNext - есть следующая страница
+func (p Paginator) HasNext() bool {
+	return p.Next != ""
+}
+
+// IsFirst - текущая страница первая
+func (p Paginator) IsFirst() bool {
+	return p.PageNumber <= 1
+}
@@ -2,12 +2,15 @@ package types
 
 import (
 	"fmt"
+	"gopkg.in/yaml.v3"
 	"time"
 )
 
 type Post struct {
 	Title        string
 	Date         time.Time
+	Lastmod      time.Time
+	GitInfo      GitInfo
 	Content      string
 	Slug         string
 	Type         string
@@ -18,22 +21,59 @@ type Post struct {
 	Summary      string
 	SummaryClean string
 	Reminder     string
+	Truncated    bool
 	Author       string
 	SourceUrl    string
 	Cover        string
 	Image        string
+	CoverSet     ImageSet
+	ImageSet     ImageSet
+	Layout       string
+	URL          string
+	Menus        PageMenus
+	IsPage       bool
+	Weight       int
+	Pinned       bool
+	Unlisted     bool
+	NoIndex      bool
+	Params       map[string]interface{}
 }
 
 type MarkdownPost struct {
-	Title       string    `yaml:"title"`
-	Date        time.Time `yaml:"date"`
-	Tags        []string  `yaml:"tags"`
-	Draft       bool      `yaml:"draft"`
-	Description string    `yaml:"description"`
-	Author      string    `yaml:"author"`
-	SourceUrl   string    `yaml:"source_url"`
-	Cover       string    `yaml:"cover"`
-	Image       string    `yaml:"image"`
+	Title       string                 `yaml:"title"`
+	Date        string                 `yaml:"date"`
+	Lastmod     string                 `yaml:"lastmod"`
+	Tags        []string               `yaml:"tags"`
+	Draft       bool                   `yaml:"draft"`
+	Description string                 `yaml:"description"`
+	Summary     string                 `yaml:"summary"`
+	Author      string                 `yaml:"author"`
+	SourceUrl   string                 `yaml:"source_url"`
+	Cover       string                 `yaml:"cover"`
+	Image       string                 `yaml:"image"`
+	Links       yaml.Node              `yaml:"links"`
+	Layout      string                 `yaml:"layout"`
+	URL         string                 `yaml:"url"`
+	Menu        PageMenus              `yaml:"menu"`
+	Keywords    string                 `yaml:"keywords"`
+	Sort        string                 `yaml:"sort"`
+	Order       string                 `yaml:"order"`
+	PerPage     int                    `yaml:"per_page"`
+	Weight      int                    `yaml:"weight"`
+	Pinned      bool                   `yaml:"pinned"`
+	Unlisted    bool                   `yaml:"unlisted"`
+	NoIndex     bool                   `yaml:"noindex"`
+	Params      map[string]interface{} `yaml:"params"`
+}
+
+type GitInfo struct {
+	Hash            string
+	AbbreviatedHash string
+	Subject         string
+	AuthorName      string
+	AuthorEmail     string
+	AuthorDate      time.Time
+	CommitDate      time.Time
 }
 
 type Posts []Post
@@ -55,10 +95,20 @@ func (posts Posts) FindByTag(tag string) Posts {
 	return foundPosts
 }
 
-func (post *Post) Permarlink() string {
+func (post Post) Permarlink() string {
+	if post.URL != "" {
+		return post.URL
+	}
+
 	return fmt.Sprintf("/%s/%s.html", post.Type, post.Slug)
 }
 
+// InMenu - страница входит в меню name
+func (post Post) InMenu(name string) bool {
+	_, ok := post.Menus[name]
+	return ok
+}
+
 func (d PostsByDate) Len() int {
 	return len(d)
 }
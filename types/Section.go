@@ -0,0 +1,14 @@
+package types
+
+type Section struct {
+	Type        string
+	Title       string
+	Description string
+	Keywords    string
+	Content     string
+	Cover       string
+	Image       string
+	Sort        string
+	Order       string
+	PerPage     int
+}